@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/koestler/go-webcam/archive"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/koestler/go-webcam/config"
+	"log"
+)
+
+func runArchive(
+	cfg *config.Config,
+	cameraClientPoolInstance *cameraClient.ClientPool,
+) *archive.Archive {
+	archiveCfg := cfg.Archive()
+	if !archiveCfg.Enabled() {
+		return nil
+	}
+
+	if cfg.LogWorkerStart() {
+		log.Printf(
+			"archive: start: directory='%s', interval=%s, cameras=%v",
+			archiveCfg.Directory(),
+			archiveCfg.Interval(),
+			archiveCfg.Cameras(),
+		)
+	}
+
+	return archive.Run(
+		archiveConfig{
+			ArchiveConfig: archiveCfg,
+			logDebug:      cfg.LogDebug(),
+		},
+		cameraClientPoolInstance,
+	)
+}
+
+type archiveConfig struct {
+	config.ArchiveConfig
+	logDebug bool
+}
+
+func (c archiveConfig) LogDebug() bool {
+	return c.logDebug
+}
@@ -0,0 +1,164 @@
+package archive
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/koestler/go-webcam/cameraClient"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	dateFormat = "2006-01-02"
+	timeFormat = "15-04-05"
+	fileSuffix = ".jpg"
+)
+
+// retentionInterval defines how often images exceeding MaxAge or MaxSize and unused time-lapse videos are deleted.
+const retentionInterval = 10 * time.Minute
+
+type Config interface {
+	Directory() string
+	Interval() time.Duration
+	MaxAge() time.Duration
+	MaxSize() int64
+	ResolutionMaxWidth() int
+	ResolutionMaxHeight() int
+	JpgQuality() int
+	Cameras() []string
+	TimelapseFps() int
+	TimelapseMaxFrames() int
+	TimelapseCacheDirectory() string
+	TimelapseCacheMaxAge() time.Duration
+	LogDebug() bool
+}
+
+type Archive struct {
+	config Config
+
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+
+	renders     singleflight.Group
+	renderMutex sync.Mutex
+}
+
+type dimension struct {
+	width  int
+	height int
+}
+
+func (d dimension) Width() int {
+	return d.width
+}
+
+func (d dimension) Height() int {
+	return d.height
+}
+
+// Run starts storing an image of every configured camera every Interval
+// to <Directory>/<camera>/<date>/<time>.jpg (UTC) and deletes images exceeding MaxAge or MaxSize.
+// Images are taken from the cache of the camera client; an image is never stored twice.
+func Run(config Config, cameraClientPoolInstance *cameraClient.ClientPool) *Archive {
+	a := &Archive{
+		config:   config,
+		shutdown: make(chan struct{}),
+	}
+
+	for _, cameraName := range config.Cameras() {
+		camera := cameraClientPoolInstance.GetClient(cameraName)
+		if camera == nil {
+			// camera failed to start
+			continue
+		}
+		a.wg.Add(1)
+		go a.recorderRoutine(camera)
+	}
+
+	a.wg.Add(1)
+	go a.retentionRoutine()
+
+	return a
+}
+
+func (a *Archive) Shutdown() {
+	close(a.shutdown)
+	a.wg.Wait()
+}
+
+func (a *Archive) Config() Config {
+	return a.config
+}
+
+func (a *Archive) recorderRoutine(camera *cameraClient.Client) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.config.Interval())
+	defer ticker.Stop()
+
+	lastUuid := ""
+	for {
+		cp := a.getImage(camera)
+		if err := cp.Err(); err != nil {
+			if a.config.LogDebug() {
+				log.Printf("archive[%s]: skip image: %s", camera.Name(), err)
+			}
+		} else if cp.Uuid() != lastUuid {
+			lastUuid = cp.Uuid()
+			if err := a.store(camera.Name(), cp.Fetched(), cp.JpgImg()); err != nil {
+				log.Printf("archive[%s]: cannot store image: %s", camera.Name(), err)
+			}
+		}
+
+		select {
+		case <-a.shutdown:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Archive) getImage(camera *cameraClient.Client) cameraClient.CameraPicture {
+	// an image fetched up to one Interval ago would mostly be the one stored on the previous tick;
+	// accept only images fetched within the last half Interval such that every tick stores a new image
+	maxAge := a.config.Interval() / 2
+	if a.config.ResolutionMaxWidth() < 1 || a.config.ResolutionMaxHeight() < 1 {
+		return camera.GetDelayedImage(maxAge)
+	}
+	return camera.GetResizedImage(
+		maxAge,
+		dimension{a.config.ResolutionMaxWidth(), a.config.ResolutionMaxHeight()},
+		a.config.JpgQuality(),
+	)
+}
+
+// store writes the image to a temporary file first such that readers never see partially written images.
+func (a *Archive) store(cameraName string, fetched time.Time, img []byte) error {
+	path := a.imagePath(cameraName, fetched)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, img, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if a.config.LogDebug() {
+		log.Printf("archive[%s]: stored %s", cameraName, path)
+	}
+	return nil
+}
+
+// imagePath uses UTC such that no image is overwritten when the clock is set back at the end of daylight saving time.
+func (a *Archive) imagePath(cameraName string, t time.Time) string {
+	t = t.UTC()
+	return filepath.Join(a.config.Directory(), cameraName, t.Format(dateFormat), t.Format(timeFormat)+fileSuffix)
+}
@@ -0,0 +1,116 @@
+package archive
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("no archived image found")
+
+// HasCamera returns true if images of the given camera are archived.
+func (a *Archive) HasCamera(cameraName string) bool {
+	for _, c := range a.config.Cameras() {
+		if c == cameraName {
+			return true
+		}
+	}
+	return false
+}
+
+// Dates returns all dates (format 2006-01-02) with archived images of the given camera, oldest first.
+func (a *Archive) Dates(cameraName string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(a.config.Directory(), cameraName))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(dateFormat, e.Name()); err != nil {
+			continue
+		}
+		dates = append(dates, e.Name())
+	}
+	sort.Strings(dates)
+	return dates, nil
+}
+
+// Timestamps returns the times of all archived images of the given camera on the given date, oldest first.
+func (a *Archive) Timestamps(cameraName, date string) ([]time.Time, error) {
+	if _, err := time.Parse(dateFormat, date); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(a.config.Directory(), cameraName, date))
+	if os.IsNotExist(err) {
+		return []time.Time{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	timestamps := make([]time.Time, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileSuffix) {
+			continue
+		}
+		t, err := time.ParseInLocation(
+			dateFormat+" "+timeFormat,
+			date+" "+strings.TrimSuffix(e.Name(), fileSuffix),
+			time.UTC,
+		)
+		if err != nil {
+			continue
+		}
+		timestamps = append(timestamps, t)
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Before(timestamps[j])
+	})
+	return timestamps, nil
+}
+
+// Closest returns the archived image of the given camera taken closest to t and the time it was taken.
+// Only images of the day of t and the days before and after are considered.
+func (a *Archive) Closest(cameraName string, t time.Time) (img []byte, taken time.Time, err error) {
+	t = t.UTC()
+
+	found := false
+	var bestDiff time.Duration
+	for _, day := range []time.Time{t.AddDate(0, 0, -1), t, t.AddDate(0, 0, 1)} {
+		timestamps, err := a.Timestamps(cameraName, day.Format(dateFormat))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		for _, ts := range timestamps {
+			diff := ts.Sub(t)
+			if diff < 0 {
+				diff = -diff
+			}
+			if !found || diff < bestDiff {
+				found = true
+				bestDiff = diff
+				taken = ts
+			}
+		}
+	}
+
+	if !found {
+		return nil, time.Time{}, ErrNotFound
+	}
+
+	img, err = os.ReadFile(a.imagePath(cameraName, taken))
+	if os.IsNotExist(err) {
+		// deleted by the retention in the meantime
+		return nil, time.Time{}, ErrNotFound
+	}
+	return img, taken, err
+}
@@ -0,0 +1,138 @@
+package archive
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type archivedFile struct {
+	path    string
+	created time.Time
+	size    int64
+}
+
+func (a *Archive) retentionRoutine() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		if a.config.MaxAge() > 0 || a.config.MaxSize() > 0 {
+			a.applyRetention()
+		}
+		a.cleanTimelapseCache()
+
+		select {
+		case <-a.shutdown:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyRetention deletes all images older than MaxAge and, while the archive is larger than MaxSize,
+// the oldest remaining images of any camera. Directories of days without any images are removed.
+func (a *Archive) applyRetention() {
+	files, err := a.listFiles()
+	if err != nil {
+		log.Printf("archive: cannot list files: %s", err)
+		return
+	}
+
+	// oldest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].created.Before(files[j].created)
+	})
+
+	var totalSize int64
+	for _, f := range files {
+		totalSize += f.size
+	}
+
+	deleted := 0
+	var minCreated time.Time
+	if maxAge := a.config.MaxAge(); maxAge > 0 {
+		minCreated = time.Now().Add(-maxAge)
+	}
+	for _, f := range files {
+		tooOld := f.created.Before(minCreated)
+		tooLarge := a.config.MaxSize() > 0 && totalSize > a.config.MaxSize()
+		if !tooOld && !tooLarge {
+			break
+		}
+
+		if err := os.Remove(f.path); err != nil {
+			log.Printf("archive: cannot delete %s: %s", f.path, err)
+			continue
+		}
+		totalSize -= f.size
+		deleted += 1
+
+		// fails when the directory is not empty yet
+		_ = os.Remove(filepath.Dir(f.path))
+	}
+
+	if a.config.LogDebug() {
+		log.Printf("archive: retention deleted %d images, remaining size=%dMiB", deleted, totalSize/1024/1024)
+	}
+}
+
+// listFiles returns all images of all cameras; other files are ignored.
+func (a *Archive) listFiles() (files []archivedFile, err error) {
+	root := a.config.Directory()
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == root && os.IsNotExist(err) {
+				// nothing stored yet
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), fileSuffix) {
+			return nil
+		}
+
+		created, ok := parseImagePath(root, path)
+		if !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		files = append(files, archivedFile{
+			path:    path,
+			created: created,
+			size:    info.Size(),
+		})
+		return nil
+	})
+	return
+}
+
+// parseImagePath returns the time an image was taken given its path <root>/<camera>/<date>/<time>.jpg.
+func parseImagePath(root, path string) (time.Time, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(
+		dateFormat+" "+timeFormat,
+		parts[1]+" "+strings.TrimSuffix(parts[2], fileSuffix),
+		time.UTC,
+	)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
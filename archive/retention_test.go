@@ -0,0 +1,121 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testConfig implements Config for the tests of this package.
+type testConfig struct {
+	directory string
+	maxAge    time.Duration
+	maxSize   int64
+}
+
+func (c testConfig) Directory() string                   { return c.directory }
+func (c testConfig) Interval() time.Duration             { return time.Minute }
+func (c testConfig) MaxAge() time.Duration               { return c.maxAge }
+func (c testConfig) MaxSize() int64                      { return c.maxSize }
+func (c testConfig) ResolutionMaxWidth() int             { return 0 }
+func (c testConfig) ResolutionMaxHeight() int            { return 0 }
+func (c testConfig) JpgQuality() int                     { return 85 }
+func (c testConfig) Cameras() []string                   { return nil }
+func (c testConfig) TimelapseFps() int                   { return 25 }
+func (c testConfig) TimelapseMaxFrames() int             { return 3600 }
+func (c testConfig) TimelapseCacheDirectory() string     { return filepath.Join(c.directory, ".timelapse") }
+func (c testConfig) TimelapseCacheMaxAge() time.Duration { return 24 * time.Hour }
+func (c testConfig) LogDebug() bool                      { return false }
+
+func storeTestImage(t *testing.T, a *Archive, cameraName string, taken time.Time, size int) string {
+	t.Helper()
+	if err := a.store(cameraName, taken, make([]byte, size)); err != nil {
+		t.Fatal(err)
+	}
+	return a.imagePath(cameraName, taken)
+}
+
+func expectExists(t *testing.T, path string, expected bool) {
+	t.Helper()
+	_, err := os.Stat(path)
+	if exists := err == nil; exists != expected {
+		t.Errorf("%s: expected exists=%t, got %t", path, expected, exists)
+	}
+}
+
+func TestRetentionMaxAge(t *testing.T) {
+	a := &Archive{config: testConfig{directory: t.TempDir(), maxAge: 24 * time.Hour}}
+
+	now := time.Now().Truncate(time.Second)
+	old := storeTestImage(t, a, "cam-a", now.Add(-72*time.Hour), 10)
+	older := storeTestImage(t, a, "cam-b", now.Add(-25*time.Hour), 10)
+	recent := storeTestImage(t, a, "cam-a", now.Add(-time.Hour), 10)
+
+	a.applyRetention()
+
+	expectExists(t, old, false)
+	expectExists(t, older, false)
+	expectExists(t, recent, true)
+
+	// directories of days without any images are removed
+	expectExists(t, filepath.Dir(old), false)
+	expectExists(t, filepath.Dir(recent), true)
+}
+
+func TestRetentionMaxSize(t *testing.T) {
+	a := &Archive{config: testConfig{directory: t.TempDir(), maxSize: 250}}
+
+	now := time.Now().Truncate(time.Second)
+	first := storeTestImage(t, a, "cam-a", now.Add(-3*time.Minute), 100)
+	second := storeTestImage(t, a, "cam-b", now.Add(-2*time.Minute), 100)
+	third := storeTestImage(t, a, "cam-a", now.Add(-time.Minute), 100)
+
+	// other files are neither deleted nor counted
+	other := filepath.Join(a.config.Directory(), "cam-a", "notes.txt")
+	if err := os.WriteFile(other, make([]byte, 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a.applyRetention()
+
+	expectExists(t, first, false)
+	expectExists(t, second, true)
+	expectExists(t, third, true)
+	expectExists(t, other, true)
+}
+
+func TestRetentionEmptyArchive(t *testing.T) {
+	a := &Archive{config: testConfig{directory: filepath.Join(t.TempDir(), "missing"), maxSize: 1}}
+	a.applyRetention()
+}
+
+func TestImagePath(t *testing.T) {
+	a := &Archive{config: testConfig{directory: "/archive"}}
+
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	// when daylight saving time ends, 02:30 local time occurs twice; both images need their own file
+	first := time.Date(2022, 10, 30, 0, 30, 0, 0, time.UTC).In(zurich)
+	second := first.Add(time.Hour)
+	if first.Format("15:04") != second.Format("15:04") {
+		t.Fatalf("expected the same local time, got %s and %s", first, second)
+	}
+
+	firstPath := a.imagePath("cam-a", first)
+	secondPath := a.imagePath("cam-a", second)
+	if firstPath == secondPath {
+		t.Errorf("expected different paths, got %s twice", firstPath)
+	}
+	if expected := "/archive/cam-a/2022-10-30/00-30-00.jpg"; firstPath != filepath.FromSlash(expected) {
+		t.Errorf("expected %s, got %s", expected, firstPath)
+	}
+
+	taken, ok := parseImagePath("/archive", secondPath)
+	if !ok || !taken.Equal(second) {
+		t.Errorf("expected %s, got %s, %t", second, taken, ok)
+	}
+}
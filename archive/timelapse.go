@@ -0,0 +1,298 @@
+package archive
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koestler/go-webcam/cameraClient"
+)
+
+// timelapseRenderTimeout limits the time ffmpeg may take to render a single time-lapse.
+const timelapseRenderTimeout = 10 * time.Minute
+
+// timelapseUnlimitedSize is used as the maximum frame size when frames are processed without a size limit.
+const timelapseUnlimitedSize = 1 << 14
+
+// TimelapseFormats maps the supported time-lapse formats to their content type.
+var TimelapseFormats = map[string]string{
+	"mp4":  "video/mp4",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+}
+
+type TimelapseOptions struct {
+	Format string // one of TimelapseFormats
+	Fps    int    // frames per second; 0 uses the configured TimelapseFps
+	Width  int    // maximum width; 0 keeps the archived resolution
+	Height int    // maximum height; 0 keeps the archived resolution
+
+	// ImageOptions are applied to every frame, eg. the privacy masks and the overlay of a view.
+	ImageOptions cameraClient.ImageOptions
+}
+
+// Timelapse renders all archived images of the given camera taken between from and to into a video
+// and returns the path of the file. Rendered videos are cached on disk; the cache key includes the
+// selected images such that newly archived images lead to a new video.
+// At most TimelapseMaxFrames images are used; for longer ranges, images are skipped evenly.
+func (a *Archive) Timelapse(cameraName string, from, to time.Time, opts TimelapseOptions) (string, error) {
+	if _, ok := TimelapseFormats[opts.Format]; !ok {
+		return "", fmt.Errorf("unsupported format: '%s'", opts.Format)
+	}
+	if opts.Fps < 1 {
+		opts.Fps = a.config.TimelapseFps()
+	}
+
+	frames, err := a.framesBetween(cameraName, from, to)
+	if err != nil {
+		return "", err
+	}
+	if len(frames) < 1 {
+		return "", ErrNotFound
+	}
+	frames = selectEvenly(frames, a.config.TimelapseMaxFrames())
+
+	cacheDir := a.config.TimelapseCacheDirectory()
+	path := filepath.Join(cacheDir, cameraName+"-"+timelapseCacheKey(frames, opts)+"."+opts.Format)
+
+	if useCachedTimelapse(path) {
+		return path, nil
+	}
+
+	// never render the same video twice; concurrent requests of the same video wait for the first one
+	_, err, _ = a.renders.Do(path, func() (interface{}, error) {
+		// the video may have been rendered since the check above
+		if useCachedTimelapse(path) {
+			return nil, nil
+		}
+
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, err
+		}
+
+		// rendering is cpu intensive; render one video at a time
+		a.renderMutex.Lock()
+		defer a.renderMutex.Unlock()
+
+		start := time.Now()
+		if err := a.render(cacheDir, frames, opts, path); err != nil {
+			return nil, err
+		}
+		if a.config.LogDebug() {
+			log.Printf("archive[%s]: rendered time-lapse of %d images, took=%.3fs, path=%s",
+				cameraName, len(frames), time.Since(start).Seconds(), path,
+			)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// useCachedTimelapse returns true when the video at path exists and marks it as used for the cache cleanup.
+func useCachedTimelapse(path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return true
+}
+
+// framesBetween returns the paths of all archived images of the given camera taken between from and to, oldest first.
+func (a *Archive) framesBetween(cameraName string, from, to time.Time) ([]string, error) {
+	from, to = from.UTC(), to.UTC()
+	fromDate, toDate := from.Format(dateFormat), to.Format(dateFormat)
+
+	dates, err := a.Dates(cameraName)
+	if err != nil {
+		return nil, err
+	}
+
+	var frames []string
+	for _, date := range dates {
+		// dates in the format 2006-01-02 compare like strings
+		if date < fromDate || date > toDate {
+			continue
+		}
+		timestamps, err := a.Timestamps(cameraName, date)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range timestamps {
+			if t.Before(from) || t.After(to) {
+				continue
+			}
+			frames = append(frames, a.imagePath(cameraName, t))
+		}
+	}
+	return frames, nil
+}
+
+// selectEvenly returns at most max of the given frames including the first and the last one.
+func selectEvenly(frames []string, max int) []string {
+	if len(frames) <= max {
+		return frames
+	}
+	ret := make([]string, max)
+	for i := range ret {
+		ret[i] = frames[i*(len(frames)-1)/(max-1)]
+	}
+	return ret
+}
+
+func timelapseCacheKey(frames []string, opts TimelapseOptions) string {
+	h := sha1.New()
+	_, _ = fmt.Fprintf(h, "%s-%d-%dx%d-%s\n", opts.Format, opts.Fps, opts.Width, opts.Height, opts.ImageOptions.CacheKey())
+	for _, f := range frames {
+		_, _ = fmt.Fprintln(h, f)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// render links the frames into a temporary directory using consecutive numbers and runs ffmpeg on them.
+// When image options are set, processed copies of the frames are written instead of links.
+// The output is moved to path only when complete.
+func (a *Archive) render(cacheDir string, frames []string, opts TimelapseOptions, path string) error {
+	tmpDir, err := os.MkdirTemp(cacheDir, "render-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.Printf("archive: cannot remove %s: %s", tmpDir, err)
+		}
+	}()
+
+	processFrames := len(opts.ImageOptions.CacheKey()) > 0
+	for i, frame := range frames {
+		framePath := filepath.Join(tmpDir, fmt.Sprintf("%06d.jpg", i))
+		if processFrames {
+			if err := a.processFrame(frame, framePath, opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		absFrame, err := filepath.Abs(frame)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(absFrame, framePath); err != nil {
+			return err
+		}
+	}
+
+	output := filepath.Join(tmpDir, "output."+opts.Format)
+	args := []string{
+		"-y",
+		"-loglevel", "error",
+		"-framerate", strconv.Itoa(opts.Fps),
+		"-i", filepath.Join(tmpDir, "%06d.jpg"),
+	}
+	args = append(args, timelapseEncoderArgs(opts)...)
+	args = append(args, output)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timelapseRenderTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return os.Rename(output, path)
+}
+
+// processFrame applies the image options to the archived image src and writes the result to dst.
+func (a *Archive) processFrame(src, dst string, opts TimelapseOptions) error {
+	img, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	taken, _ := parseImagePath(a.config.Directory(), src)
+	dim := dimension{opts.Width, opts.Height}
+	if dim.width < 1 || dim.height < 1 {
+		dim = dimension{timelapseUnlimitedSize, timelapseUnlimitedSize}
+	}
+
+	img, err = cameraClient.ResizeJpg(img, dim, a.config.JpgQuality(), opts.ImageOptions, taken)
+	if err != nil {
+		return fmt.Errorf("cannot process %s: %s", src, err)
+	}
+	return os.WriteFile(dst, img, 0644)
+}
+
+func timelapseEncoderArgs(opts TimelapseOptions) []string {
+	scale := "null"
+	if opts.Width > 0 && opts.Height > 0 {
+		// only scale down, keep the aspect ratio
+		scale = fmt.Sprintf(
+			"scale=w='min(iw,%d)':h='min(ih,%d)':force_original_aspect_ratio=decrease",
+			opts.Width, opts.Height,
+		)
+	}
+
+	switch opts.Format {
+	case "gif":
+		return []string{
+			"-filter_complex", scale + ",split[a][b];[a]palettegen[p];[b][p]paletteuse",
+			"-loop", "0",
+		}
+	case "webp":
+		return []string{
+			"-vf", scale,
+			"-c:v", "libwebp",
+			"-quality", "75",
+			"-loop", "0",
+		}
+	default:
+		return []string{
+			// h264 with yuv420p needs even dimensions
+			"-vf", scale + ",scale=trunc(iw/2)*2:trunc(ih/2)*2",
+			"-c:v", "libx264",
+			"-pix_fmt", "yuv420p",
+			"-movflags", "+faststart",
+		}
+	}
+}
+
+// cleanTimelapseCache deletes all rendered videos which were not used for TimelapseCacheMaxAge
+// and leftovers of interrupted renders.
+func (a *Archive) cleanTimelapseCache() {
+	cacheDir := a.config.TimelapseCacheDirectory()
+	entries, err := os.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		log.Printf("archive: cannot list time-lapse cache: %s", err)
+		return
+	}
+
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		maxAge := a.config.TimelapseCacheMaxAge()
+		if e.IsDir() {
+			maxAge = timelapseRenderTimeout
+		}
+		if time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(cacheDir, e.Name())); err != nil {
+			log.Printf("archive: cannot delete %s: %s", e.Name(), err)
+		}
+	}
+}
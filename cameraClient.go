@@ -16,30 +16,9 @@ func runCameraClient(
 	countStarted := 0
 
 	for _, camera := range cfg.Cameras() {
-		if cfg.LogWorkerStart() {
-			log.Printf(
-				"cameraClient[%s]: start: address='%s'",
-				camera.Name(),
-				camera.Address(),
-			)
-		}
-
-		cameraConfig := cameraClientConfig{
-			CameraConfig: *camera,
-			logDebug:     cfg.LogDebug(),
-		}
-
-		if client, err := cameraClient.RunClient(&cameraConfig); err != nil {
-			log.Printf("cameraClient[%s]: start failed: %s", camera.Name(), err)
-		} else {
+		if client := startCameraClient(cfg, camera); client != nil {
 			cameraClientPoolInstance.AddClient(client)
 			countStarted += 1
-			if cfg.LogWorkerStart() {
-				log.Printf(
-					"cameraClient[%s]: started",
-					camera.Name(),
-				)
-			}
 		}
 	}
 
@@ -50,11 +29,63 @@ func runCameraClient(
 	return cameraClientPoolInstance
 }
 
+func startCameraClient(cfg *config.Config, camera *config.CameraConfig) *cameraClient.Client {
+	if cfg.LogWorkerStart() {
+		log.Printf(
+			"cameraClient[%s]: start: address='%s'",
+			camera.Name(),
+			camera.Address(),
+		)
+	}
+
+	client, err := cameraClient.RunClient(getCameraClientConfig(cfg, camera))
+	if err != nil {
+		log.Printf("cameraClient[%s]: start failed: %s", camera.Name(), err)
+		return nil
+	}
+
+	if cfg.LogWorkerStart() {
+		log.Printf(
+			"cameraClient[%s]: started",
+			camera.Name(),
+		)
+	}
+	return client
+}
+
+func getCameraClientConfig(cfg *config.Config, camera *config.CameraConfig) *cameraClientConfig {
+	return &cameraClientConfig{
+		CameraConfig: *camera,
+		logDebug:     cfg.LogDebug(),
+	}
+}
+
 type cameraClientConfig struct {
 	config.CameraConfig
 	logDebug bool
 }
 
+func (cc *cameraClientConfig) MotionDetection() cameraClient.MotionDetectionConfig {
+	return motionDetectionConfig{cc.CameraConfig.MotionDetection()}
+}
+
+type motionDetectionConfig struct {
+	config.MotionDetectionConfig
+}
+
+func (mc motionDetectionConfig) IgnoreMasks() []cameraClient.Region {
+	masks := mc.MotionDetectionConfig.IgnoreMasks()
+	ret := make([]cameraClient.Region, len(masks))
+	for i, m := range masks {
+		ret[i] = cameraClient.Region{Left: m.Left(), Top: m.Top(), Width: m.Width(), Height: m.Height()}
+	}
+	return ret
+}
+
+func (cc *cameraClientConfig) Transform() cameraClient.TransformConfig {
+	return cc.CameraConfig.Transform()
+}
+
 func (cc *cameraClientConfig) LogDebug() bool {
 	return cc.logDebug
 }
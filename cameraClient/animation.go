@@ -0,0 +1,101 @@
+package cameraClient
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+// AnimationFormats lists the formats ComposeAnimation can encode; webp is encoded using ffmpeg.
+var AnimationFormats = []string{"gif", "webp"}
+
+const (
+	animationMinFrameDelay = 20 * time.Millisecond
+	animationMaxFrameDelay = time.Second
+)
+
+// AnimationCacheKey returns a key which is equal for animations composed of the same frames.
+func AnimationCacheKey(frames []CameraPicture) string {
+	h := sha1.New()
+	for _, f := range frames {
+		_, _ = fmt.Fprintf(h, "%s\n", f.Uuid())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ComposeAnimation decodes the frames, processes each of them like a resized image and encodes them as an
+// animation in the format of the options which loops forever. Every frame is shown until the next frame
+// was fetched, but at least animationMinFrameDelay and at most animationMaxFrameDelay. The animation
+// expires with its newest frame.
+func ComposeAnimation(frames []CameraPicture, dim Dimension, jpgQuality int, options ImageOptions) *cameraPicture {
+	animation := &cameraPicture{
+		uuid:    AnimationCacheKey(frames),
+		variant: options.CacheKey() + "-animation",
+		format:  options.format(),
+	}
+
+	if len(frames) < 1 {
+		animation.err = fmt.Errorf("no frames available")
+		return animation
+	}
+	newest := frames[len(frames)-1]
+	animation.fetched = newest.Fetched()
+	animation.fetchDuration = newest.FetchDuration()
+	animation.expires = newest.Expires()
+
+	imgs := make([]image.Image, len(frames))
+	var size image.Point
+	for i := len(frames) - 1; i >= 0; i-- {
+		decodedImg, err := jpeg.Decode(bytes.NewReader(frames[i].JpgImg()))
+		if err != nil {
+			animation.err = err
+			return animation
+		}
+		img := processImage(decodedImg, dim, options, frames[i].Fetched())
+
+		// all frames get the size of the newest frame in case the camera resolution has changed
+		if i == len(frames)-1 {
+			size = img.Bounds().Size()
+		} else if img.Bounds().Size() != size {
+			img = imaging.Resize(img, size.X, size.Y, imaging.Box)
+		}
+		imgs[i] = img
+	}
+
+	delays := make([]time.Duration, len(frames))
+	var total time.Duration
+	for i := range frames {
+		if i+1 < len(frames) {
+			delays[i] = frames[i+1].Fetched().Sub(frames[i].Fetched())
+		} else if i > 0 {
+			delays[i] = delays[i-1]
+		}
+		delays[i] = max(animationMinFrameDelay, min(animationMaxFrameDelay, delays[i]))
+		total += delays[i]
+	}
+
+	switch animation.format {
+	case "gif":
+		gifDelays := make([]int, len(delays))
+		for i, d := range delays {
+			gifDelays[i] = int(d / (10 * time.Millisecond))
+		}
+		animation.encodedImg, animation.err = encodeGif(imgs, gifDelays)
+	case "webp":
+		// ffmpeg shows all frames for the same duration
+		frameRate := float64(len(frames)) / total.Seconds()
+		animation.encodedImg, animation.err = encodeWithFfmpeg(imgs, animation.format, jpgQuality, frameRate)
+	default:
+		animation.err = fmt.Errorf("unsupported animation format: '%s'", animation.format)
+		return animation
+	}
+
+	animation.decodedImg = imgs[len(imgs)-1]
+	return animation
+}
@@ -1,15 +1,31 @@
 package cameraClient
 
 import (
+	"context"
+	"errors"
+	"sync/atomic"
 	"time"
 )
 
+// ErrShutdown is the error of all images requested from a client after it was shut down.
+var ErrShutdown = errors.New("camera was shut down")
+
 type Config interface {
 	Name() string
+	Type() string
 	Address() string
+	User() string
+	Password() string
+	Timeout() time.Duration
+	FileOrder() string
+	ResolutionWidth() int
+	ResolutionHeight() int
 	RefreshInterval() time.Duration
 	PreemptiveFetch() time.Duration
+	RecentFrames() int
 	ExpireEarly() time.Duration
+	MotionDetection() MotionDetectionConfig
+	Transform() TransformConfig
 	LogDebug() bool
 }
 
@@ -17,26 +33,45 @@ type Client struct {
 	// configuration
 	config Config
 
-	rtsp    rtspState
+	source  ImageSource
 	raw     rawState
 	delayed delayedState
 	resize  resizeState
+	motion  motionState
+
+	subscriptions       subscriptions[CameraPicture]
+	motionSubscriptions subscriptions[MotionEvent]
+	stats               *stats
 }
 
 func RunClient(config Config) (*Client, error) {
+	source, err := createImageSource(config)
+	if err != nil {
+		return nil, err
+	}
+	return RunClientWithSource(config, source), nil
+}
+
+// RunClientWithSource starts a client fetching its images from the given source instead
+// of the one selected by config.Type().
+func RunClientWithSource(config Config, source ImageSource) *Client {
 	client := &Client{
 		config:  config,
-		rtsp:    createRtspState(),
-		raw:     createRawState(),
+		source:  source,
+		raw:     createRawState(config.RecentFrames()),
 		delayed: createDelayedState(),
 		resize:  createResizeState(),
+
+		subscriptions:       createSubscriptions[CameraPicture](),
+		motionSubscriptions: createSubscriptions[MotionEvent](),
+		stats:               createStats(),
 	}
 
 	go client.rawImageRoutine()
 	go client.delayedImageRoutine()
 	go client.resizedImageRoutine()
 
-	return client, nil
+	return client
 }
 
 func (c *Client) Shutdown() {
@@ -48,7 +83,57 @@ func (c *Client) Shutdown() {
 	<-c.raw.closed
 	<-c.delayed.closed
 	<-c.resize.closed
-	c.rtsp.Close()
+	c.source.Close()
+}
+
+// fetchStuckMargin is added to the camera Timeout before a running fetch is considered stuck.
+const fetchStuckMargin = 10 * time.Second
+
+// Alive returns true when the raw, delayed and resize go routines respond within the given timeout and no fetch
+// has been running for longer than the camera Timeout plus fetchStuckMargin. The raw and the delayed image
+// routines do not respond while a fetch is in progress; they are considered alive as long as the fetch is not stuck.
+func (c *Client) Alive(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if !ping(ctx, c.resize.ping) {
+		return false
+	}
+	if !ping(ctx, c.raw.ping) && !c.fetching() {
+		return false
+	}
+	if !ping(ctx, c.delayed.ping) && !c.fetching() {
+		return false
+	}
+	return !c.fetchStuck()
+}
+
+func ping(ctx context.Context, ping chan chan struct{}) bool {
+	response := make(chan struct{})
+	select {
+	case ping <- response:
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case <-response:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (c *Client) fetching() bool {
+	return atomic.LoadInt64(&c.raw.fetchStarted) != 0
+}
+
+func (c *Client) fetchStuck() bool {
+	started := atomic.LoadInt64(&c.raw.fetchStarted)
+	return started != 0 && time.Since(time.Unix(0, started)) > c.Config().Timeout()+fetchStuckMargin
+}
+
+// Done returns a channel which is closed when the client is shut down.
+func (c *Client) Done() <-chan struct{} {
+	return c.raw.shutdown
 }
 
 func (c *Client) Name() string {
@@ -60,21 +145,102 @@ func (c *Client) Config() Config {
 }
 
 func (c *Client) GetRawImage() *cameraPicture {
-	response := make(chan *cameraPicture)
-	c.raw.readRequestChannel <- rawImageReadRequest{response}
-	return <-response
+	response := make(chan *cameraPicture, 1)
+	select {
+	case c.raw.readRequestChannel <- rawImageReadRequest{false, response}:
+	case <-c.raw.shutdown:
+		return shutdownPicture()
+	}
+	return awaitPicture(response, c.raw.shutdown)
+}
+
+// FetchImage fetches a new raw image regardless of the cache. All cached delayed and resized images are dropped
+// such that the next request of any view gets the new image.
+func (c *Client) FetchImage() *cameraPicture {
+	response := make(chan *cameraPicture, 1)
+	select {
+	case c.raw.readRequestChannel <- rawImageReadRequest{true, response}:
+	case <-c.raw.shutdown:
+		return shutdownPicture()
+	}
+	cp := awaitPicture(response, c.raw.shutdown)
+	select {
+	case c.delayed.invalidateChannel <- struct{}{}:
+	case <-c.delayed.shutdown:
+	}
+	select {
+	case c.resize.invalidateChannel <- struct{}{}:
+	case <-c.resize.shutdown:
+	}
+	return cp
+}
+
+// PreemptiveFetchFor keeps fetching images every RefreshInterval for the given duration even when
+// no images are requested. A duration <= 0 stops a running preemptive fetch.
+func (c *Client) PreemptiveFetchFor(duration time.Duration) {
+	select {
+	case c.raw.preemptiveRequestChannel <- rawPreemptiveRequest{duration}:
+	case <-c.raw.shutdown:
+	}
+}
+
+// GetRecentFrames returns up to count of the most recently fetched raw images, the oldest first.
+// Only the jpg images are available; DecodedImg returns nil. After a shutdown, no frames are returned.
+func (c *Client) GetRecentFrames(count int) []CameraPicture {
+	response := make(chan []CameraPicture, 1)
+	select {
+	case c.raw.recentFramesRequestChannel <- rawRecentFramesRequest{count, response}:
+	case <-c.raw.shutdown:
+		return nil
+	}
+	select {
+	case frames := <-response:
+		return frames
+	case <-c.raw.shutdown:
+		return nil
+	}
 }
 
 func (c *Client) GetDelayedImage(refreshInterval time.Duration) *cameraPicture {
-	response := make(chan *cameraPicture)
-	c.delayed.readRequestChannel <- delayedImageReadRequest{refreshInterval, response}
-	return <-response
+	response := make(chan *cameraPicture, 1)
+	select {
+	case c.delayed.readRequestChannel <- delayedImageReadRequest{refreshInterval, response}:
+	case <-c.delayed.shutdown:
+		return shutdownPicture()
+	}
+	return awaitPicture(response, c.delayed.shutdown)
 }
 
 func (c *Client) GetResizedImage(refreshInterval time.Duration, dim Dimension, jpgQuality int) *cameraPicture {
-	response := make(chan *cameraPicture)
-	c.resize.readRequestChannel <- resizedImageReadRequest{
-		resizedImageRequest{refreshInterval, dim, jpgQuality},
-		response}
-	return <-response
+	return c.GetResizedImageWithOptions(refreshInterval, dim, jpgQuality, ImageOptions{})
+}
+
+// GetResizedImageWithOptions works like GetResizedImage and additionally applies the given options.
+func (c *Client) GetResizedImageWithOptions(
+	refreshInterval time.Duration, dim Dimension, jpgQuality int, options ImageOptions,
+) *cameraPicture {
+	response := make(chan *cameraPicture, 1)
+	select {
+	case c.resize.readRequestChannel <- resizedImageReadRequest{
+		resizedImageRequest{refreshInterval, dim, jpgQuality, options},
+		response}:
+	case <-c.resize.shutdown:
+		return shutdownPicture()
+	}
+	return awaitPicture(response, c.resize.shutdown)
+}
+
+// awaitPicture waits for the response of a go routine unless it is shut down.
+// All response channels are buffered such that the go routines never block when the requester has given up.
+func awaitPicture(response <-chan *cameraPicture, shutdown <-chan struct{}) *cameraPicture {
+	select {
+	case cp := <-response:
+		return cp
+	case <-shutdown:
+		return shutdownPicture()
+	}
+}
+
+func shutdownPicture() *cameraPicture {
+	return &cameraPicture{err: ErrShutdown}
 }
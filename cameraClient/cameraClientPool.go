@@ -1,6 +1,9 @@
 package cameraClient
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 type ClientPool struct {
 	clients      map[string]*Client
@@ -39,3 +42,18 @@ func (p *ClientPool) GetClient(clientName string) *Client {
 	defer p.clientsMutex.RUnlock()
 	return p.clients[clientName]
 }
+
+// GetClients returns all clients ordered by name.
+func (p *ClientPool) GetClients() []*Client {
+	p.clientsMutex.RLock()
+	defer p.clientsMutex.RUnlock()
+
+	ret := make([]*Client, 0, len(p.clients))
+	for _, c := range p.clients {
+		ret = append(ret, c)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name() < ret[j].Name()
+	})
+	return ret
+}
@@ -0,0 +1,55 @@
+package cameraClient
+
+import (
+	"time"
+)
+
+// testConfig implements Config, MotionDetectionConfig and TransformConfig for the tests of this package.
+type testConfig struct {
+	name            string
+	cameraType      string
+	address         string
+	user            string
+	password        string
+	timeout         time.Duration
+	fileOrder       string
+	width           int
+	height          int
+	refreshInterval time.Duration
+	recentFrames    int
+
+	motionEnabled bool
+	threshold     int
+	minArea       float64
+	cooldown      time.Duration
+	ignoreMasks   []Region
+}
+
+func (c *testConfig) Name() string                           { return c.name }
+func (c *testConfig) Type() string                           { return c.cameraType }
+func (c *testConfig) Address() string                        { return c.address }
+func (c *testConfig) User() string                           { return c.user }
+func (c *testConfig) Password() string                       { return c.password }
+func (c *testConfig) Timeout() time.Duration                 { return c.timeout }
+func (c *testConfig) FileOrder() string                      { return c.fileOrder }
+func (c *testConfig) ResolutionWidth() int                   { return c.width }
+func (c *testConfig) ResolutionHeight() int                  { return c.height }
+func (c *testConfig) RefreshInterval() time.Duration         { return c.refreshInterval }
+func (c *testConfig) PreemptiveFetch() time.Duration         { return 0 }
+func (c *testConfig) RecentFrames() int                      { return c.recentFrames }
+func (c *testConfig) ExpireEarly() time.Duration             { return 0 }
+func (c *testConfig) MotionDetection() MotionDetectionConfig { return c }
+func (c *testConfig) Transform() TransformConfig             { return c }
+func (c *testConfig) LogDebug() bool                         { return false }
+
+func (c *testConfig) Enabled() bool           { return c.motionEnabled }
+func (c *testConfig) GridWidth() int          { return 32 }
+func (c *testConfig) GridHeight() int         { return 18 }
+func (c *testConfig) Threshold() int          { return c.threshold }
+func (c *testConfig) MinArea() float64        { return c.minArea }
+func (c *testConfig) Cooldown() time.Duration { return c.cooldown }
+func (c *testConfig) IgnoreMasks() []Region   { return c.ignoreMasks }
+
+func (c *testConfig) Rotate() int             { return 0 }
+func (c *testConfig) Flip() string            { return "none" }
+func (c *testConfig) LensCorrection() float64 { return 0 }
@@ -7,21 +7,29 @@ import (
 
 type CameraPicture interface {
 	JpgImg() []byte
+	Img() []byte
+	Format() string
 	DecodedImg() image.Image
 	Fetched() time.Time
+	FetchDuration() time.Duration
 	Expires() time.Time
 	Expired(delay time.Duration) bool
 	Uuid() string
+	Variant() string
 	Err() error
 }
 
 type cameraPicture struct {
-	jpgImg     []byte
-	decodedImg image.Image
-	fetched    time.Time
-	expires    time.Time
-	uuid       string
-	err        error
+	jpgImg        []byte
+	decodedImg    image.Image
+	fetched       time.Time
+	fetchDuration time.Duration
+	expires       time.Time
+	uuid          string
+	variant       string // cache key of the ImageOptions applied; images with equal uuid and variant are equal
+	format        string // one of ImageFormats; empty: jpg
+	encodedImg    []byte // the image encoded in format; only set when format is not jpg
+	err           error
 }
 
 type cameraPictureMap map[string]*cameraPicture
@@ -30,6 +38,21 @@ func (cp cameraPicture) JpgImg() []byte {
 	return cp.jpgImg
 }
 
+// Img returns the image encoded in Format. For formats other than jpg, JpgImg returns nil.
+func (cp cameraPicture) Img() []byte {
+	if cp.Format() == ImageFormatJpg {
+		return cp.jpgImg
+	}
+	return cp.encodedImg
+}
+
+func (cp cameraPicture) Format() string {
+	if len(cp.format) < 1 {
+		return ImageFormatJpg
+	}
+	return cp.format
+}
+
 func (cp cameraPicture) DecodedImg() image.Image {
 	return cp.decodedImg
 }
@@ -42,6 +65,10 @@ func (cp cameraPicture) Fetched() time.Time {
 	return cp.fetched
 }
 
+func (cp cameraPicture) FetchDuration() time.Duration {
+	return cp.fetchDuration
+}
+
 func (cp cameraPicture) Expires() time.Time {
 	return cp.expires
 }
@@ -54,6 +81,10 @@ func (cp cameraPicture) Uuid() string {
 	return cp.uuid
 }
 
+func (cp cameraPicture) Variant() string {
+	return cp.variant
+}
+
 func (cp cameraPicture) Err() error {
 	return cp.err
 }
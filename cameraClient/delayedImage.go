@@ -7,8 +7,12 @@ import (
 
 type delayedState struct {
 	readRequestChannel chan delayedImageReadRequest
+	invalidateChannel  chan struct{}
 	cache              cameraPictureMap
 
+	// liveness check
+	ping chan chan struct{}
+
 	// shutdown handling
 	shutdown chan struct{}
 	closed   chan struct{}
@@ -22,7 +26,9 @@ type delayedImageReadRequest struct {
 func createDelayedState() delayedState {
 	return delayedState{
 		readRequestChannel: make(chan delayedImageReadRequest, 16),
+		invalidateChannel:  make(chan struct{}, 16),
 		cache:              make(cameraPictureMap),
+		ping:               make(chan chan struct{}),
 		shutdown:           make(chan struct{}),
 		closed:             make(chan struct{}),
 	}
@@ -34,6 +40,11 @@ func (c *Client) delayedImageRoutine() {
 		select {
 		case readRequest := <-c.delayed.readRequestChannel:
 			c.handleDelayedImageReadRequest(readRequest)
+		case <-c.delayed.invalidateChannel:
+			c.delayed.cache = make(cameraPictureMap)
+			c.stats.cacheEntries(StageDelayed, 0)
+		case response := <-c.delayed.ping:
+			close(response)
 		case <-c.delayed.shutdown:
 			return
 		}
@@ -54,6 +65,7 @@ func (c *Client) handleDelayedImageReadRequest(request delayedImageReadRequest)
 				time.Until(cp.expires),
 			)
 		}
+		c.stats.cacheAccess(StageDelayed, true, len(c.delayed.cache))
 		request.response <- cp
 	} else {
 		if c.Config().LogDebug() {
@@ -63,16 +75,18 @@ func (c *Client) handleDelayedImageReadRequest(request delayedImageReadRequest)
 		rawImg := c.GetRawImage()
 
 		delayedImage := &cameraPicture{
-			jpgImg:     rawImg.JpgImg(),
-			decodedImg: rawImg.DecodedImg(),
-			fetched:    rawImg.Fetched(),
-			expires:    laterTime(rawImg.Expires(), rawImg.Fetched().Add(refreshInterval)),
-			uuid:       rawImg.Uuid(),
-			err:        rawImg.Err(),
+			jpgImg:        rawImg.JpgImg(),
+			decodedImg:    rawImg.DecodedImg(),
+			fetched:       rawImg.Fetched(),
+			fetchDuration: rawImg.FetchDuration(),
+			expires:       laterTime(rawImg.Expires(), rawImg.Fetched().Add(refreshInterval)),
+			uuid:          rawImg.Uuid(),
+			err:           rawImg.Err(),
 		}
 
 		request.response <- delayedImage
 		c.delayed.cache[cacheKey] = delayedImage
+		c.stats.cacheAccess(StageDelayed, false, len(c.delayed.cache))
 	}
 }
 
@@ -0,0 +1,160 @@
+package cameraClient
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fileSource reads jpeg images from the local filesystem. The address is either a single file
+// or a directory which is scanned on every fetch.
+type fileSource struct {
+	config Config
+	isDir  bool
+
+	// used when FileOrder is cycle; name of the last returned file
+	lastFile string
+}
+
+func createFileSource(config Config) (*fileSource, error) {
+	info, err := os.Stat(config.Address())
+	if err != nil {
+		return nil, fmt.Errorf("cannot open address: %s", err)
+	}
+
+	return &fileSource{
+		config: config,
+		isDir:  info.IsDir(),
+	}, nil
+}
+
+// fileNewestCandidates limits how many older files are tried when the newest files are still being written.
+const fileNewestCandidates = 3
+
+func (fs *fileSource) Close() {}
+
+func (fs *fileSource) GetRawImage() (img []byte, err error) {
+	if !fs.isDir {
+		return os.ReadFile(fs.config.Address())
+	}
+
+	if fs.config.FileOrder() == "cycle" {
+		file, err := fs.nextFile()
+		if err != nil {
+			return nil, err
+		}
+		return os.ReadFile(filepath.Join(fs.config.Address(), file))
+	}
+
+	files, err := fs.newestFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	// an uploader (eg. a camera pushing snapshots by FTP) may still be writing the newest file;
+	// use the newest file which is complete instead
+	for i, file := range files {
+		if i >= fileNewestCandidates {
+			break
+		}
+		img, err = os.ReadFile(filepath.Join(fs.config.Address(), file))
+		if err == nil && !isCompleteJpeg(img) {
+			err = fmt.Errorf("file %s is incomplete", file)
+		}
+		if err == nil {
+			return img, nil
+		}
+	}
+	return nil, err
+}
+
+// newestFiles returns the names of all jpg files, the most recently modified first.
+func (fs *fileSource) newestFiles() ([]string, error) {
+	entries, err := fs.jpgEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	type file struct {
+		name string
+		info os.FileInfo
+	}
+	files := make([]file, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			// file was removed in the meantime
+			continue
+		}
+		files = append(files, file{e.Name(), info})
+	}
+
+	if len(files) < 1 {
+		return nil, fmt.Errorf("no jpg file found in %s", fs.config.Address())
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().After(files[j].info.ModTime())
+	})
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.name
+	}
+	return names, nil
+}
+
+// isCompleteJpeg returns true when img starts with the start of image and ends with the end of image marker.
+// Trailing padding, as written by some cameras, is ignored.
+func isCompleteJpeg(img []byte) bool {
+	img = bytes.TrimRight(img, "\x00\r\n ")
+	return bytes.HasPrefix(img, []byte{0xFF, 0xD8}) && bytes.HasSuffix(img, []byte{0xFF, 0xD9})
+}
+
+func (fs *fileSource) nextFile() (string, error) {
+	entries, err := fs.jpgEntries()
+	if err != nil {
+		return "", err
+	}
+	if len(entries) < 1 {
+		return "", fmt.Errorf("no jpg file found in %s", fs.config.Address())
+	}
+
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	sort.Strings(names)
+
+	// take the first file sorted after the last one; wrap around at the end
+	i := sort.SearchStrings(names, fs.lastFile)
+	if i < len(names) && names[i] == fs.lastFile {
+		i++
+	}
+	if i >= len(names) {
+		i = 0
+	}
+
+	fs.lastFile = names[i]
+	return fs.lastFile, nil
+}
+
+func (fs *fileSource) jpgEntries() (ret []os.DirEntry, err error) {
+	entries, err := os.ReadDir(fs.config.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".jpg", ".jpeg":
+			ret = append(ret, e)
+		}
+	}
+	return
+}
@@ -0,0 +1,154 @@
+package cameraClient
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestFile writes a file to dir and sets its modification time to the given age.
+func writeTestFile(t *testing.T, dir, name string, content []byte, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testFileSource(t *testing.T, address, fileOrder string) *fileSource {
+	t.Helper()
+	fs, err := createFileSource(&testConfig{name: "cam-file", address: address, fileOrder: fileOrder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func TestFileSourceSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	img := testJpeg(t)
+	writeTestFile(t, dir, "snap.jpg", img, 0)
+
+	fs := testFileSource(t, filepath.Join(dir, "snap.jpg"), "newest")
+	got, err := fs.GetRawImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(got, img) {
+		t.Errorf("unexpected image")
+	}
+}
+
+func TestFileSourceMissingAddress(t *testing.T) {
+	if _, err := createFileSource(&testConfig{address: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Errorf("expected an error for a missing address")
+	}
+}
+
+func TestFileSourceNewest(t *testing.T) {
+	dir := t.TempDir()
+	img := testJpeg(t)
+	older := append(testJpeg(t), 0)
+	writeTestFile(t, dir, "a.jpg", older, 2*time.Minute)
+	writeTestFile(t, dir, "b.jpg", img, time.Minute)
+	writeTestFile(t, dir, "c.txt", []byte("not an image"), 0)
+	writeTestFile(t, dir, ".hidden.jpg", older, 0)
+
+	fs := testFileSource(t, dir, "newest")
+	got, err := fs.GetRawImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(got, img) {
+		t.Errorf("expected the newest jpg file")
+	}
+
+	// a file which is still being written is skipped
+	writeTestFile(t, dir, "d.jpg", img[:len(img)/2], 0)
+	got, err = fs.GetRawImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(got, img) {
+		t.Errorf("expected the newest complete jpg file")
+	}
+}
+
+func TestFileSourceNewestAllIncomplete(t *testing.T) {
+	dir := t.TempDir()
+	img := testJpeg(t)
+	for i, name := range []string{"a.jpg", "b.jpg", "c.jpg", "d.jpg"} {
+		content := img[:len(img)/2]
+		if name == "a.jpg" {
+			// complete, but older than the files which are tried
+			content = img
+		}
+		writeTestFile(t, dir, name, content, time.Duration(4-i)*time.Minute)
+	}
+
+	fs := testFileSource(t, dir, "newest")
+	if _, err := fs.GetRawImage(); err == nil {
+		t.Errorf("expected an error when the %d newest files are incomplete", fileNewestCandidates)
+	}
+}
+
+func TestFileSourceCycle(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.jpg", "a.jpeg", "c.JPG"} {
+		writeTestFile(t, dir, name, []byte(name), 0)
+	}
+
+	fs := testFileSource(t, dir, "cycle")
+	for _, expected := range []string{"a.jpeg", "b.jpg", "c.JPG", "a.jpeg"} {
+		got, err := fs.GetRawImage()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(got) != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+
+	// a removed file does not break the cycle
+	if err := os.Remove(filepath.Join(dir, "b.jpg")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := fs.GetRawImage(); err != nil || string(got) != "c.JPG" {
+		t.Errorf("expected c.JPG, got %s, %v", got, err)
+	}
+}
+
+func TestFileSourceEmptyDirectory(t *testing.T) {
+	for _, fileOrder := range []string{"newest", "cycle"} {
+		fs := testFileSource(t, t.TempDir(), fileOrder)
+		if _, err := fs.GetRawImage(); err == nil {
+			t.Errorf("%s: expected an error for an empty directory", fileOrder)
+		}
+	}
+}
+
+func TestIsCompleteJpeg(t *testing.T) {
+	img := testJpeg(t)
+	tests := []struct {
+		name     string
+		img      []byte
+		expected bool
+	}{
+		{"complete", img, true},
+		{"padded", append(append([]byte{}, img...), 0, 0, '\r', '\n'), true},
+		{"truncated", img[:len(img)-10], false},
+		{"empty", nil, false},
+		{"no jpeg", []byte("GIF89a"), false},
+	}
+	for _, tc := range tests {
+		if got := isCompleteJpeg(tc.img); got != tc.expected {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package cameraClient
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// httpMaxImageSize limits the size of a response such that a misbehaving camera cannot exhaust the memory.
+const httpMaxImageSize = 32 << 20
+
+type httpSource struct {
+	config Config
+	client *http.Client
+
+	// authentication state; credentials are only sent once the camera asked for them.
+	// The last digest challenge is reused until the camera rejects it.
+	basic      bool
+	digest     *digestChallenge
+	nonceCount uint32
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+func createHttpSource(config Config) (*httpSource, error) {
+	u, err := url.Parse(config.Address())
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %s", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("address must start with http:// or https://, got '%s'", config.Address())
+	}
+
+	return &httpSource{
+		config: config,
+		client: &http.Client{
+			Timeout: config.Timeout(),
+		},
+	}, nil
+}
+
+func (hs *httpSource) Close() {
+	hs.client.CloseIdleConnections()
+}
+
+func (hs *httpSource) GetRawImage() (img []byte, err error) {
+	res, err := hs.do()
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusUnauthorized && len(hs.config.User()) > 0 {
+		// (re)authenticate using the challenge given by the camera
+		challenge := res.Header.Get("WWW-Authenticate")
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+
+		if strings.HasPrefix(strings.ToLower(challenge), "digest ") {
+			hs.digest = parseDigestChallenge(challenge)
+			hs.nonceCount = 0
+			hs.basic = false
+		} else {
+			hs.digest = nil
+			hs.basic = true
+		}
+
+		res, err = hs.do()
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http status: %s", res.Status)
+	}
+
+	img, err = io.ReadAll(io.LimitReader(res.Body, httpMaxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(img) > httpMaxImageSize {
+		return nil, fmt.Errorf("image is larger than %d bytes", httpMaxImageSize)
+	}
+
+	// cameras send all kinds of content types (image/jpg, application/octet-stream, ...); check the content instead
+	if !bytes.HasPrefix(img, []byte{0xFF, 0xD8, 0xFF}) {
+		return nil, fmt.Errorf("response is not a jpeg image, content-type: '%s'", res.Header.Get("Content-Type"))
+	}
+
+	return img, nil
+}
+
+func (hs *httpSource) do() (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, hs.config.Address(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "image/jpeg")
+
+	if user := hs.config.User(); len(user) > 0 {
+		if hs.digest != nil {
+			hs.nonceCount += 1
+			req.Header.Set("Authorization", hs.digest.authorization(
+				user, hs.config.Password(), req.Method, req.URL.RequestURI(), hs.nonceCount,
+			))
+		} else if hs.basic {
+			req.SetBasicAuth(user, hs.config.Password())
+		}
+	}
+
+	return hs.client.Do(req)
+}
+
+func parseDigestChallenge(header string) *digestChallenge {
+	params := make(map[string]string)
+	for _, part := range splitDigestParams(header[len("digest "):]) {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+
+	qop := ""
+	for _, q := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(q) == "auth" {
+			qop = "auth"
+		}
+	}
+
+	return &digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+		qop:       qop,
+	}
+}
+
+// splitDigestParams splits at commas which are not within a quoted string.
+func splitDigestParams(s string) (ret []string) {
+	quoted := false
+	start := 0
+	for i, r := range s {
+		switch r {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				ret = append(ret, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, s[start:])
+}
+
+func (dc *digestChallenge) authorization(user, password, method, uri string, nonceCount uint32) string {
+	cnonce := randomHex(8)
+	nc := fmt.Sprintf("%08x", nonceCount)
+
+	ha1 := md5Hex(user + ":" + dc.realm + ":" + password)
+	if strings.EqualFold(dc.algorithm, "MD5-sess") {
+		ha1 = md5Hex(ha1 + ":" + dc.nonce + ":" + cnonce)
+	}
+	ha2 := md5Hex(method + ":" + uri)
+
+	var response string
+	if dc.qop == "auth" {
+		response = md5Hex(ha1 + ":" + dc.nonce + ":" + nc + ":" + cnonce + ":" + dc.qop + ":" + ha2)
+	} else {
+		response = md5Hex(ha1 + ":" + dc.nonce + ":" + ha2)
+	}
+
+	h := fmt.Sprintf(
+		`Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		user, dc.realm, dc.nonce, uri, response,
+	)
+	if len(dc.algorithm) > 0 {
+		h += ", algorithm=" + dc.algorithm
+	}
+	if len(dc.opaque) > 0 {
+		h += fmt.Sprintf(`, opaque="%s"`, dc.opaque)
+	}
+	if dc.qop == "auth" {
+		h += fmt.Sprintf(`, qop=auth, nc=%s, cnonce="%s"`, nc, cnonce)
+	}
+	return h
+}
+
+func md5Hex(s string) string {
+	h := md5.Sum([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,167 @@
+package cameraClient
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testJpeg(t *testing.T) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func testHttpSource(t *testing.T, url, user, password string) *httpSource {
+	t.Helper()
+	hs, err := createHttpSource(&testConfig{
+		name:     "cam-http",
+		address:  url,
+		user:     user,
+		password: password,
+		timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(hs.Close)
+	return hs
+}
+
+func testMd5(s string) string {
+	h := md5.Sum([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestHttpSourceDigestAuth(t *testing.T) {
+	const realm, nonce, opaque = "cam, realm", "abc123", "xyz"
+	img := testJpeg(t)
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		requests = append(requests, auth)
+
+		params := make(map[string]string)
+		if strings.HasPrefix(auth, "Digest ") {
+			for _, part := range splitDigestParams(auth[len("Digest "):]) {
+				kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+				if len(kv) == 2 {
+					params[kv[0]] = strings.Trim(kv[1], `"`)
+				}
+			}
+		}
+
+		ha1 := testMd5("user:" + realm + ":secret")
+		ha2 := testMd5(r.Method + ":" + r.URL.RequestURI())
+		expected := testMd5(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+		if params["username"] != "user" || params["opaque"] != opaque || params["response"] != expected {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+				`Digest realm="%s", qop="auth,auth-int", nonce="%s", opaque="%s"`, realm, nonce, opaque,
+			))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(img)
+	}))
+	defer server.Close()
+
+	hs := testHttpSource(t, server.URL+"/snap.jpg?channel=1", "user", "secret")
+	for i := 0; i < 2; i++ {
+		got, err := hs.GetRawImage()
+		if err != nil {
+			t.Fatalf("fetch %d: unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(got, img) {
+			t.Fatalf("fetch %d: unexpected image", i)
+		}
+	}
+
+	// the first request is sent without credentials, the challenge is reused for the second fetch
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d: %v", len(requests), requests)
+	}
+	if len(requests[0]) > 0 {
+		t.Errorf("expected the first request without credentials, got '%s'", requests[0])
+	}
+	if !strings.Contains(requests[1], "nc=00000001") || !strings.Contains(requests[2], "nc=00000002") {
+		t.Errorf("expected an increasing nonce count, got %v", requests[1:])
+	}
+}
+
+func TestHttpSourceBasicAuth(t *testing.T) {
+	img := testJpeg(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="cam"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// cameras often send a wrong content type
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(img)
+	}))
+	defer server.Close()
+
+	hs := testHttpSource(t, server.URL, "user", "secret")
+	for i := 0; i < 2; i++ {
+		if _, err := hs.GetRawImage(); err != nil {
+			t.Fatalf("fetch %d: unexpected error: %s", i, err)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestHttpSourceWrongCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="cam"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	hs := testHttpSource(t, server.URL, "user", "wrong")
+	if _, err := hs.GetRawImage(); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected a 401 error, got %v", err)
+	}
+}
+
+func TestHttpSourceNoJpeg(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html>login</html>"))
+	}))
+	defer server.Close()
+
+	hs := testHttpSource(t, server.URL, "", "")
+	if _, err := hs.GetRawImage(); err == nil || !strings.Contains(err.Error(), "not a jpeg") {
+		t.Errorf("expected a not a jpeg error, got %v", err)
+	}
+}
+
+func TestParseDigestChallenge(t *testing.T) {
+	dc := parseDigestChallenge(`Digest realm="a, b", nonce="n1", algorithm=MD5-sess, qop="auth-int, auth"`)
+	if dc.realm != "a, b" || dc.nonce != "n1" || dc.algorithm != "MD5-sess" || dc.qop != "auth" {
+		t.Errorf("unexpected challenge: %+v", dc)
+	}
+
+	dc = parseDigestChallenge(`Digest realm="cam", nonce="n2", qop="auth-int"`)
+	if dc.qop != "" {
+		t.Errorf("expected qop auth-int to be ignored, got '%s'", dc.qop)
+	}
+}
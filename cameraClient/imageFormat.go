@@ -0,0 +1,150 @@
+package cameraClient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+// ImageFormatJpg is the default format; images in all formats but jpg and gif are encoded using ffmpeg.
+const ImageFormatJpg = "jpg"
+
+// ImageFormats maps the supported image formats to their content type.
+var ImageFormats = map[string]string{
+	ImageFormatJpg: "image/jpeg",
+	"webp":         "image/webp",
+	"avif":         "image/avif",
+	"gif":          "image/gif",
+}
+
+// imageEncodeTimeout limits the time ffmpeg may take to encode a single image.
+const imageEncodeTimeout = 30 * time.Second
+
+// encodeImage encodes img in the given format; quality (1-100) is mapped to the quality setting of the encoder.
+func encodeImage(img image.Image, format string, quality int) ([]byte, error) {
+	if len(format) < 1 || format == ImageFormatJpg {
+		var b bytes.Buffer
+		w := bufio.NewWriter(&b)
+		if err := jpeg.Encode(w, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+		if err := w.Flush(); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	if format == "gif" {
+		return encodeGif([]image.Image{img}, nil)
+	}
+	if _, ok := ImageFormats[format]; !ok {
+		return nil, fmt.Errorf("unsupported image format: '%s'", format)
+	}
+	return encodeWithFfmpeg([]image.Image{img}, format, quality, 1)
+}
+
+// gifPalette contains 256 colors evenly spread over the color space; gifs cannot use more colors per frame.
+var gifPalette = palette.Plan9
+
+// encodeGif encodes the frames as a gif looping forever; delays are given in 100ths of a second per frame.
+// The colors are reduced to a fixed palette using dithering.
+func encodeGif(frames []image.Image, delays []int) ([]byte, error) {
+	anim := gif.GIF{}
+	for i, img := range frames {
+		b := img.Bounds()
+		paletted := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), gifPalette)
+		draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, b.Min)
+		anim.Image = append(anim.Image, paletted)
+		delay := 0
+		if i < len(delays) {
+			delay = delays[i]
+		}
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	var b bytes.Buffer
+	if err := gif.EncodeAll(&b, &anim); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// encodeWithFfmpeg pipes the raw pixels of the frames into ffmpeg and returns the encoded image.
+// All frames must have the same size; more than one frame results in an animation shown at frameRate.
+// The output is written to a temporary file since the avif muxer needs a seekable output.
+func encodeWithFfmpeg(frames []image.Image, format string, quality int, frameRate float64) ([]byte, error) {
+	if len(frames) < 1 {
+		return nil, fmt.Errorf("no frames to encode")
+	}
+	b := frames[0].Bounds()
+	var pixels bytes.Buffer
+	for _, img := range frames {
+		pixels.Write(imaging.Clone(img).Pix)
+	}
+
+	output, err := os.CreateTemp("", "go-webcam-*."+format)
+	if err != nil {
+		return nil, err
+	}
+	_ = output.Close()
+	defer os.Remove(output.Name())
+
+	args := []string{
+		"-y",
+		"-loglevel", "error",
+		"-f", "rawvideo",
+		"-pix_fmt", "rgba",
+		"-s", fmt.Sprintf("%dx%d", b.Dx(), b.Dy()),
+		"-framerate", strconv.FormatFloat(frameRate, 'f', 3, 64),
+		"-i", "-",
+		"-frames:v", strconv.Itoa(len(frames)),
+	}
+	args = append(args, imageEncoderArgs(format, quality)...)
+	args = append(args, output.Name())
+
+	ctx, cancel := context.WithTimeout(context.Background(), imageEncodeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdin = &pixels
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return os.ReadFile(output.Name())
+}
+
+func imageEncoderArgs(format string, quality int) []string {
+	switch format {
+	case "avif":
+		// crf ranges from 0 (lossless) to 63; a jpg quality of 85 roughly corresponds to a crf of 30
+		crf := minInt(63, 2*(100-quality))
+		return []string{
+			// yuv420p needs even dimensions
+			"-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2",
+			"-c:v", "libaom-av1",
+			"-still-picture", "1",
+			"-cpu-used", "8",
+			"-crf", strconv.Itoa(crf),
+			"-pix_fmt", "yuv420p",
+		}
+	default:
+		return []string{
+			"-c:v", "libwebp",
+			"-quality", strconv.Itoa(quality),
+			"-loop", "0",
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cameraClient
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+// ImageOptions defines processing steps of the resize stage in addition to scaling.
+// All options are part of the cache key of the resized images.
+type ImageOptions struct {
+	Crop         *Region       // nil: full image; applied before scaling
+	PrivacyMasks []PrivacyMask // applied before the overlay
+	Overlay      *Overlay      // nil: no overlay
+	Format       string        // one of ImageFormats; empty: jpg
+}
+
+// CacheKey returns a string which is equal for equal options; it is empty when no option is set.
+func (o ImageOptions) CacheKey() string {
+	var parts []string
+	if o.Crop != nil {
+		parts = append(parts, "crop:"+o.Crop.cacheKey())
+	}
+	for _, m := range o.PrivacyMasks {
+		parts = append(parts, "mask:"+m.cacheKey())
+	}
+	if o.Overlay != nil {
+		parts = append(parts, "overlay:"+o.Overlay.cacheKey())
+	}
+	if o.format() != ImageFormatJpg {
+		parts = append(parts, "format:"+o.Format)
+	}
+	return strings.Join(parts, "-")
+}
+
+func (o ImageOptions) format() string {
+	if len(o.Format) < 1 {
+		return ImageFormatJpg
+	}
+	return o.Format
+}
+
+// Region is a rectangle given in percent of the image width and height.
+type Region struct {
+	Left   float64
+	Top    float64
+	Width  float64
+	Height float64
+}
+
+func (r Region) cacheKey() string {
+	return fmt.Sprintf("%g,%g,%g,%g", r.Left, r.Top, r.Width, r.Height)
+}
+
+// contains returns true when the point given in percent lies within the region.
+func (r Region) contains(x, y float64) bool {
+	return x >= r.Left && x < r.Left+r.Width && y >= r.Top && y < r.Top+r.Height
+}
+
+// crop returns the part of img within the region; the returned image has the bounds (0, 0)-(width, height).
+func (r Region) crop(img image.Image) image.Image {
+	b := img.Bounds()
+	rect := image.Rect(
+		b.Min.X+int(r.Left*float64(b.Dx())/100),
+		b.Min.Y+int(r.Top*float64(b.Dy())/100),
+		b.Min.X+int((r.Left+r.Width)*float64(b.Dx())/100),
+		b.Min.Y+int((r.Top+r.Height)*float64(b.Dy())/100),
+	).Intersect(b)
+	if rect.Empty() {
+		return img
+	}
+	return imaging.Crop(img, rect)
+}
+
+// apply runs all processing steps on the already scaled image; fetched is the time the image was taken.
+func (o ImageOptions) apply(img image.Image, fetched time.Time) image.Image {
+	if len(o.PrivacyMasks) > 0 {
+		img = applyPrivacyMasks(img, o.PrivacyMasks)
+	}
+	if o.Overlay != nil {
+		img = o.Overlay.draw(img, fetched)
+	}
+	return img
+}
@@ -0,0 +1,111 @@
+package cameraClient
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+func TestImageOptionsCacheKey(t *testing.T) {
+	crop := &Region{Left: 50, Top: 0, Width: 50, Height: 100}
+	mask := PrivacyMask{Polygon: [][2]float64{{0, 0}, {10, 0}, {10, 10}}}
+	overlay := &Overlay{Title: "east", Position: "top-left"}
+
+	if got := (ImageOptions{}).CacheKey(); got != "" {
+		t.Errorf("expected an empty cache key without options, got '%s'", got)
+	}
+	if got := (ImageOptions{Format: ImageFormatJpg}).CacheKey(); got != "" {
+		t.Errorf("expected an empty cache key for jpg, got '%s'", got)
+	}
+
+	options := []ImageOptions{
+		{Crop: crop},
+		{Crop: &Region{Left: 0, Top: 0, Width: 50, Height: 100}},
+		{PrivacyMasks: []PrivacyMask{mask}},
+		{PrivacyMasks: []PrivacyMask{mask, mask}},
+		{Overlay: overlay},
+		{Format: "webp"},
+		{Crop: crop, PrivacyMasks: []PrivacyMask{mask}, Overlay: overlay, Format: "webp"},
+	}
+	keys := make(map[string]int)
+	for i, o := range options {
+		key := o.CacheKey()
+		if j, ok := keys[key]; ok {
+			t.Errorf("options %d and %d have the same cache key '%s'", i, j, key)
+		}
+		keys[key] = i
+
+		// equal options must have equal keys, even when the pointers differ
+		copied := o
+		if o.Crop != nil {
+			c := *o.Crop
+			copied.Crop = &c
+		}
+		if copied.CacheKey() != key {
+			t.Errorf("options %d: expected an equal cache key for equal options", i)
+		}
+	}
+}
+
+func TestRegionCrop(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+
+	tests := []struct {
+		name     string
+		region   Region
+		expected image.Point
+	}{
+		{"right half", Region{Left: 50, Top: 0, Width: 50, Height: 100}, image.Pt(100, 100)},
+		{"center", Region{Left: 25, Top: 25, Width: 50, Height: 50}, image.Pt(100, 50)},
+		{"clipped", Region{Left: 80, Top: 80, Width: 50, Height: 50}, image.Pt(40, 20)},
+		{"outside", Region{Left: 120, Top: 0, Width: 10, Height: 10}, image.Pt(200, 100)},
+	}
+	for _, tc := range tests {
+		cropped := tc.region.crop(img)
+		if got := cropped.Bounds().Size(); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+
+	// images with an offset are cropped relative to their bounds
+	sub := img.SubImage(image.Rect(100, 0, 200, 100))
+	if got := (Region{Left: 0, Top: 0, Width: 50, Height: 50}).crop(sub).Bounds(); got != image.Rect(0, 0, 50, 50) {
+		t.Errorf("expected the bounds (0,0)-(50,50), got %v", got)
+	}
+}
+
+func TestRegionContains(t *testing.T) {
+	r := Region{Left: 10, Top: 20, Width: 30, Height: 40}
+	tests := []struct {
+		x, y     float64
+		expected bool
+	}{
+		{10, 20, true},
+		{39.9, 59.9, true},
+		{40, 30, false},
+		{20, 60, false},
+		{5, 30, false},
+	}
+	for _, tc := range tests {
+		if got := r.contains(tc.x, tc.y); got != tc.expected {
+			t.Errorf("%g,%g: expected %t, got %t", tc.x, tc.y, tc.expected, got)
+		}
+	}
+}
+
+func TestProcessImageCropsBeforeScaling(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1280, 720))
+	options := ImageOptions{Crop: &Region{Left: 0, Top: 0, Width: 25, Height: 50}}
+
+	// the cropped region of 320x360 is scaled down to fit into 160x160
+	processed := processImage(img, dimension{width: 160, height: 160}, options, time.Now())
+	if got := processed.Bounds().Size(); got != image.Pt(142, 160) {
+		t.Errorf("expected 142x160, got %v", got)
+	}
+
+	// images are never scaled up
+	processed = processImage(img, dimension{width: 1920, height: 1080}, options, time.Now())
+	if got := processed.Bounds().Size(); got != image.Pt(320, 360) {
+		t.Errorf("expected 320x360, got %v", got)
+	}
+}
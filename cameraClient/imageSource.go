@@ -0,0 +1,35 @@
+package cameraClient
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImageSource fetches a single, jpeg encoded frame from a camera.
+type ImageSource interface {
+	GetRawImage() ([]byte, error)
+	Close()
+}
+
+// frameSource is implemented by sources which receive frames on their own and may return the same frame more
+// than once. received identifies the frame; it is the time the frame was received from the camera.
+type frameSource interface {
+	GetRawFrame() (img []byte, received time.Time, err error)
+}
+
+func createImageSource(config Config) (ImageSource, error) {
+	switch config.Type() {
+	case "rtsp":
+		return createRtspSource(config)
+	case "rtspStream":
+		return createRtspStreamSource(config)
+	case "http":
+		return createHttpSource(config)
+	case "file":
+		return createFileSource(config)
+	case "testPattern":
+		return createTestPatternSource(config)
+	default:
+		return nil, fmt.Errorf("unknown camera type: '%s'", config.Type())
+	}
+}
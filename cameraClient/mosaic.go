@@ -0,0 +1,102 @@
+package cameraClient
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+var (
+	mosaicBackground      = color.Gray{Y: 32}
+	mosaicLabelBackground = color.RGBA{A: 160}
+)
+
+// MosaicTile is a camera image placed into a mosaic.
+type MosaicTile struct {
+	Title   string
+	Picture CameraPicture
+}
+
+// MosaicGrid returns the number of columns and rows used to arrange count tiles in a grid which is as square as possible.
+func MosaicGrid(count int) (columns, rows int) {
+	if count < 1 {
+		return 0, 0
+	}
+	columns = int(math.Ceil(math.Sqrt(float64(count))))
+	rows = (count + columns - 1) / columns
+	return
+}
+
+// MosaicCacheKey returns a key which is equal for mosaics composed of the same images.
+func MosaicCacheKey(tiles []MosaicTile) string {
+	h := sha1.New()
+	for _, t := range tiles {
+		_, _ = fmt.Fprintf(h, "%q-%s-%s\n", t.Title, t.Picture.Uuid(), t.Picture.Variant())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ComposeMosaic arranges the tiles in a grid as given by MosaicGrid and labels each tile with its title.
+// Every cell has the size of the largest tile; smaller tiles are centered. Tiles of cameras which failed
+// are shown as an empty cell with the error. The mosaic is as old as its oldest and expires with its first tile.
+func ComposeMosaic(tiles []MosaicTile, jpgQuality int) *cameraPicture {
+	columns, rows := MosaicGrid(len(tiles))
+
+	mosaic := &cameraPicture{
+		uuid:    MosaicCacheKey(tiles),
+		variant: "mosaic",
+	}
+
+	var cell image.Point
+	for i, t := range tiles {
+		cp := t.Picture
+		if i == 0 || cp.Fetched().Before(mosaic.fetched) {
+			mosaic.fetched = cp.Fetched()
+		}
+		if i == 0 || cp.Expires().Before(mosaic.expires) {
+			mosaic.expires = cp.Expires()
+		}
+		if cp.Err() == nil && cp.DecodedImg() != nil {
+			size := cp.DecodedImg().Bounds().Size()
+			cell.X = maxInt(cell.X, size.X)
+			cell.Y = maxInt(cell.Y, size.Y)
+		}
+	}
+	if cell.X < 1 || cell.Y < 1 {
+		mosaic.err = fmt.Errorf("no camera image available")
+		if len(tiles) > 0 && tiles[0].Picture.Err() != nil {
+			mosaic.err = tiles[0].Picture.Err()
+		}
+		return mosaic
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, columns*cell.X, rows*cell.Y))
+	draw.Draw(img, img.Bounds(), image.NewUniform(mosaicBackground), image.Point{}, draw.Src)
+
+	fontSize := maxInt(10, cell.Y/20)
+	for i, t := range tiles {
+		origin := image.Pt((i%columns)*cell.X, (i/columns)*cell.Y)
+		// draw into the cell only, such that long labels are cut instead of covering the next tile
+		cellImg := img.SubImage(image.Rectangle{Min: origin, Max: origin.Add(cell)}).(*image.RGBA)
+		label := t.Title
+
+		if tile := t.Picture.DecodedImg(); t.Picture.Err() == nil && tile != nil {
+			b := tile.Bounds()
+			offset := origin.Add(cell.Sub(b.Size()).Div(2))
+			draw.Draw(cellImg, image.Rectangle{Min: offset, Max: offset.Add(b.Size())}, tile, b.Min, draw.Src)
+		} else if t.Picture.Err() != nil {
+			label += ": " + t.Picture.Err().Error()
+		}
+
+		pt := origin.Add(image.Pt(fontSize/2, fontSize/2))
+		drawTextBox(cellImg, pt, fontSize, label, color.White, mosaicLabelBackground)
+	}
+
+	mosaic.decodedImg = img
+	mosaic.jpgImg, mosaic.err = encodeImage(img, ImageFormatJpg, jpgQuality)
+	return mosaic
+}
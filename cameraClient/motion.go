@@ -0,0 +1,185 @@
+package cameraClient
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"time"
+)
+
+// motionSamplesPerCell limits the number of pixels per row and column of a grid cell used to compute its brightness.
+const motionSamplesPerCell = 16
+
+type MotionDetectionConfig interface {
+	Enabled() bool
+	GridWidth() int
+	GridHeight() int
+	Threshold() int
+	MinArea() float64
+	Cooldown() time.Duration
+	IgnoreMasks() []Region // in percent of the transformed image
+}
+
+// MotionEvent is sent whenever motion starts or ends on a camera.
+type MotionEvent struct {
+	Camera string
+	Active bool      // true when motion started, false when it ended
+	Start  time.Time // when the motion was first detected
+	End    time.Time // when the motion ended; zero while active
+	Area   float64   // largest percentage of changed cells seen during this motion
+}
+
+type motionState struct {
+	// average brightness per cell of the previous frame
+	previous       []int
+	previousBounds image.Rectangle
+	ignored        []bool
+
+	active     bool
+	start      time.Time
+	lastMotion time.Time
+	area       float64
+}
+
+// SubscribeMotionEvents returns a channel receiving an event whenever motion starts or ends.
+// Events are dropped when the receiver does not keep up. unsubscribe must be called when done.
+func (c *Client) SubscribeMotionEvents() (events <-chan MotionEvent, unsubscribe func()) {
+	return c.motionSubscriptions.subscribe(16)
+}
+
+// detectMotion compares the given frame to the previous one; it must only be called by the raw image routine.
+func (c *Client) detectMotion(img image.Image, now time.Time) {
+	cfg := c.Config().MotionDetection()
+	if !cfg.Enabled() || img == nil {
+		return
+	}
+
+	m := &c.motion
+	grid := brightnessGrid(img, cfg.GridWidth(), cfg.GridHeight())
+	previous := m.previous
+	m.previous = grid
+
+	if bounds := img.Bounds(); previous == nil || bounds != m.previousBounds {
+		// first frame or the resolution changed; nothing to compare to
+		m.previousBounds = bounds
+		m.ignored = ignoredCells(cfg.GridWidth(), cfg.GridHeight(), cfg.IgnoreMasks())
+		return
+	}
+
+	changed, total := 0, 0
+	for i := range grid {
+		if m.ignored[i] {
+			continue
+		}
+		total += 1
+		if diff := grid[i] - previous[i]; diff >= cfg.Threshold() || -diff >= cfg.Threshold() {
+			changed += 1
+		}
+	}
+	if total < 1 {
+		return
+	}
+	area := 100 * float64(changed) / float64(total)
+
+	if area >= cfg.MinArea() {
+		m.lastMotion = now
+		if !m.active {
+			m.active = true
+			m.start = now
+			m.area = area
+			log.Printf("cameraClient[%s]: motion started, area=%.1f%%", c.Name(), area)
+			c.publishMotionEvent()
+		} else if area > m.area {
+			m.area = area
+		}
+	} else if m.active && now.Sub(m.lastMotion) >= cfg.Cooldown() {
+		m.active = false
+		log.Printf("cameraClient[%s]: motion ended, duration=%s, area=%.1f%%",
+			c.Name(), m.lastMotion.Sub(m.start), m.area,
+		)
+		c.publishMotionEvent()
+	}
+}
+
+func (c *Client) publishMotionEvent() {
+	m := &c.motion
+	event := MotionEvent{
+		Camera: c.Name(),
+		Active: m.active,
+		Start:  m.start,
+		Area:   m.area,
+	}
+	if !m.active {
+		event.End = m.lastMotion
+	}
+	c.stats.motion(event)
+	c.motionSubscriptions.publish(event)
+}
+
+// brightnessGrid divides the image into gridWidth x gridHeight cells and returns the average brightness of each.
+func brightnessGrid(img image.Image, gridWidth, gridHeight int) []int {
+	b := img.Bounds()
+	luma := lumaFunc(img)
+	grid := make([]int, gridWidth*gridHeight)
+
+	for gy := 0; gy < gridHeight; gy++ {
+		y0 := b.Min.Y + b.Dy()*gy/gridHeight
+		y1 := b.Min.Y + b.Dy()*(gy+1)/gridHeight
+		stepY := maxInt(1, (y1-y0)/motionSamplesPerCell)
+		for gx := 0; gx < gridWidth; gx++ {
+			x0 := b.Min.X + b.Dx()*gx/gridWidth
+			x1 := b.Min.X + b.Dx()*(gx+1)/gridWidth
+			stepX := maxInt(1, (x1-x0)/motionSamplesPerCell)
+
+			sum, n := 0, 0
+			for y := y0; y < y1; y += stepY {
+				for x := x0; x < x1; x += stepX {
+					sum += luma(x, y)
+					n += 1
+				}
+			}
+			if n > 0 {
+				grid[gy*gridWidth+gx] = sum / n
+			}
+		}
+	}
+
+	return grid
+}
+
+// ignoredCells returns for each grid cell whether its center lies within one of the masks.
+// The masks are given in percent; the grid divides every image into the same cells, so the bounds are not needed.
+func ignoredCells(gridWidth, gridHeight int, masks []Region) []bool {
+	ignored := make([]bool, gridWidth*gridHeight)
+	for gy := 0; gy < gridHeight; gy++ {
+		for gx := 0; gx < gridWidth; gx++ {
+			x := 100 * float64(2*gx+1) / float64(2*gridWidth)
+			y := 100 * float64(2*gy+1) / float64(2*gridHeight)
+			for _, mask := range masks {
+				if mask.contains(x, y) {
+					ignored[gy*gridWidth+gx] = true
+					break
+				}
+			}
+		}
+	}
+	return ignored
+}
+
+// lumaFunc returns a function reading the brightness (0-255) of a pixel; decoded jpgs are read without conversion.
+func lumaFunc(img image.Image) func(x, y int) int {
+	switch img := img.(type) {
+	case *image.YCbCr:
+		return func(x, y int) int {
+			return int(img.Y[img.YOffset(x, y)])
+		}
+	case *image.Gray:
+		return func(x, y int) int {
+			return int(img.Pix[img.PixOffset(x, y)])
+		}
+	default:
+		return func(x, y int) int {
+			return int(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package cameraClient
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+)
+
+// testFrame returns a gray image of 320x180 pixels with the given region in percent painted white.
+func testFrame(changed *Region) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 320, 180))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 100}), image.Point{}, draw.Src)
+	if changed != nil {
+		rect := image.Rect(
+			int(changed.Left*3.2), int(changed.Top*1.8),
+			int((changed.Left+changed.Width)*3.2), int((changed.Top+changed.Height)*1.8),
+		)
+		draw.Draw(img, rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	}
+	return img
+}
+
+func testMotionClient(cfg *testConfig) (*Client, <-chan MotionEvent) {
+	cfg.motionEnabled = true
+	c := &Client{
+		config:              cfg,
+		motionSubscriptions: createSubscriptions[MotionEvent](),
+		stats:               createStats(),
+	}
+	events, _ := c.SubscribeMotionEvents()
+	return c, events
+}
+
+func expectNoMotionEvent(t *testing.T, events <-chan MotionEvent) {
+	t.Helper()
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected motion event: %+v", e)
+	default:
+	}
+}
+
+func TestDetectMotion(t *testing.T) {
+	c, events := testMotionClient(&testConfig{
+		name:      "cam-motion",
+		threshold: 20,
+		minArea:   5,
+		cooldown:  10 * time.Second,
+	})
+
+	now := time.Now()
+	quarter := &Region{Left: 0, Top: 0, Width: 50, Height: 50}
+
+	c.detectMotion(testFrame(nil), now)
+	c.detectMotion(testFrame(nil), now.Add(time.Second))
+	expectNoMotionEvent(t, events)
+
+	c.detectMotion(testFrame(quarter), now.Add(2*time.Second))
+	start := receive(t, events)
+	if !start.Active || start.Camera != "cam-motion" || !start.Start.Equal(now.Add(2*time.Second)) {
+		t.Errorf("unexpected start event: %+v", start)
+	}
+	if start.Area < 24 || start.Area > 26 {
+		t.Errorf("expected an area of about 25%%, got %.1f%%", start.Area)
+	}
+
+	// no change within the cooldown keeps the motion active
+	c.detectMotion(testFrame(quarter), now.Add(5*time.Second))
+	expectNoMotionEvent(t, events)
+
+	c.detectMotion(testFrame(quarter), now.Add(12*time.Second))
+	end := receive(t, events)
+	if end.Active || !end.End.Equal(now.Add(2*time.Second)) {
+		t.Errorf("unexpected end event: %+v", end)
+	}
+
+	if s := c.Stats(); s.MotionEvents != 1 || s.MotionActive {
+		t.Errorf("expected 1 inactive motion event in the stats, got %d, active=%t", s.MotionEvents, s.MotionActive)
+	}
+}
+
+func TestDetectMotionBelowThresholds(t *testing.T) {
+	c, events := testMotionClient(&testConfig{
+		name:      "cam-motion",
+		threshold: 20,
+		minArea:   50,
+		cooldown:  time.Second,
+	})
+
+	now := time.Now()
+	c.detectMotion(testFrame(nil), now)
+
+	// 25% of the cells changed, but 50% are required
+	c.detectMotion(testFrame(&Region{Left: 0, Top: 0, Width: 50, Height: 50}), now.Add(time.Second))
+	expectNoMotionEvent(t, events)
+
+	// a small change of the brightness is ignored
+	img := testFrame(nil)
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 110}), image.Point{}, draw.Src)
+	c.detectMotion(img, now.Add(2*time.Second))
+	expectNoMotionEvent(t, events)
+}
+
+func TestDetectMotionIgnoreMasks(t *testing.T) {
+	c, events := testMotionClient(&testConfig{
+		name:        "cam-motion",
+		threshold:   20,
+		minArea:     1,
+		cooldown:    time.Second,
+		ignoreMasks: []Region{{Left: 0, Top: 0, Width: 50, Height: 100}},
+	})
+
+	now := time.Now()
+	c.detectMotion(testFrame(nil), now)
+	c.detectMotion(testFrame(&Region{Left: 10, Top: 10, Width: 30, Height: 80}), now.Add(time.Second))
+	expectNoMotionEvent(t, events)
+
+	c.detectMotion(testFrame(&Region{Left: 60, Top: 10, Width: 30, Height: 80}), now.Add(2*time.Second))
+	if e := receive(t, events); !e.Active {
+		t.Errorf("expected motion outside of the mask, got %+v", e)
+	}
+}
+
+func TestDetectMotionResolutionChange(t *testing.T) {
+	c, events := testMotionClient(&testConfig{
+		name:      "cam-motion",
+		threshold: 20,
+		minArea:   1,
+		cooldown:  time.Second,
+	})
+
+	now := time.Now()
+	c.detectMotion(testFrame(nil), now)
+
+	// a frame of a different resolution is not compared to the previous one
+	c.detectMotion(image.NewGray(image.Rect(0, 0, 640, 360)), now.Add(time.Second))
+	expectNoMotionEvent(t, events)
+}
+
+func TestIgnoredCells(t *testing.T) {
+	ignored := ignoredCells(4, 2, []Region{{Left: 0, Top: 0, Width: 50, Height: 50}, {Left: 80, Top: 60, Width: 20, Height: 40}})
+	expected := []bool{
+		true, true, false, false,
+		false, false, false, true,
+	}
+	for i := range expected {
+		if ignored[i] != expected[i] {
+			t.Errorf("cell %d: expected %t, got %t", i, expected[i], ignored[i])
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package cameraClient
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+var (
+	overlayForeground = color.White
+	overlayBackground = color.RGBA{A: 128}
+)
+
+// Overlay defines text and a logo drawn onto a corner of resized images.
+type Overlay struct {
+	Title           string         // empty: no title
+	TimestampFormat string         // go time layout of the fetched time; empty: no timestamp
+	Location        *time.Location // time zone of the timestamp
+	Text            string         // empty: no free text
+	Logo            string         // path of a png or jpg image; empty: no logo
+	Position        string         // top-left, top-right, bottom-left or bottom-right
+	FontSize        int            // in pixels; 0: relative to the image height
+}
+
+func (o Overlay) cacheKey() string {
+	location := ""
+	if o.Location != nil {
+		location = o.Location.String()
+	}
+	return fmt.Sprintf("%q-%q-%s-%q-%q-%s-%d",
+		o.Title, o.TimestampFormat, location, o.Text, o.Logo, o.Position, o.FontSize,
+	)
+}
+
+func (o Overlay) lines(fetched time.Time) (lines []string) {
+	first := o.Title
+	if len(o.TimestampFormat) > 0 && !fetched.IsZero() {
+		if o.Location != nil {
+			fetched = fetched.In(o.Location)
+		}
+		if len(first) > 0 {
+			first += " "
+		}
+		first += fetched.Format(o.TimestampFormat)
+	}
+	if len(first) > 0 {
+		lines = append(lines, first)
+	}
+	if len(o.Text) > 0 {
+		lines = append(lines, o.Text)
+	}
+	return
+}
+
+// draw returns a copy of img with the overlay drawn onto it.
+func (o Overlay) draw(img image.Image, fetched time.Time) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+
+	fontSize := o.FontSize
+	if fontSize < 1 {
+		fontSize = maxInt(10, b.Dy()/30)
+	}
+	margin := fontSize / 2
+
+	var logo image.Image
+	if len(o.Logo) > 0 {
+		logo = getOverlayLogo(o.Logo, b.Dx()/4, b.Dy()/4)
+	}
+	lines := o.lines(fetched)
+
+	// the logo is stacked above the text lines; compute the total height to align the stack to the bottom
+	height := 0
+	if logo != nil {
+		height += logo.Bounds().Dy()
+	}
+	for _, line := range lines {
+		height += textBoxSize(fontSize, line).Y
+	}
+
+	left := strings.HasSuffix(o.Position, "left")
+	y := b.Max.Y - margin - height
+	if strings.HasPrefix(o.Position, "top") {
+		y = b.Min.Y + margin
+	}
+	corner := func(size image.Point) image.Point {
+		if left {
+			return image.Pt(b.Min.X+margin, y)
+		}
+		return image.Pt(b.Max.X-margin-size.X, y)
+	}
+
+	if logo != nil {
+		size := logo.Bounds().Size()
+		pt := corner(size)
+		draw.Draw(dst, image.Rectangle{Min: pt, Max: pt.Add(size)}, logo, logo.Bounds().Min, draw.Over)
+		y += size.Y
+	}
+	for _, line := range lines {
+		box := drawTextBox(dst, corner(textBoxSize(fontSize, line)), fontSize, line, overlayForeground, overlayBackground)
+		y += box.Dy()
+	}
+
+	return dst
+}
+
+type overlayLogoEntry struct {
+	modTime time.Time
+	img     image.Image
+}
+
+var (
+	overlayLogoMutex sync.Mutex
+	overlayLogoCache = make(map[string]overlayLogoEntry) // path -> decoded logo
+)
+
+// getOverlayLogo returns the decoded logo scaled down to fit into maxWidth x maxHeight.
+// Logos are cached and loaded again when the file changes. nil is returned when the logo cannot be loaded.
+func getOverlayLogo(path string, maxWidth, maxHeight int) image.Image {
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Printf("cameraClient: cannot load overlay logo: %s", err)
+		return nil
+	}
+
+	overlayLogoMutex.Lock()
+	entry, ok := overlayLogoCache[path]
+	overlayLogoMutex.Unlock()
+
+	if !ok || !entry.modTime.Equal(info.ModTime()) {
+		img, err := imaging.Open(path)
+		if err != nil {
+			log.Printf("cameraClient: cannot load overlay logo: %s", err)
+			return nil
+		}
+		entry = overlayLogoEntry{modTime: info.ModTime(), img: img}
+
+		overlayLogoMutex.Lock()
+		overlayLogoCache[path] = entry
+		overlayLogoMutex.Unlock()
+	}
+
+	if maxWidth < 1 || maxHeight < 1 {
+		return nil
+	}
+	if s := entry.img.Bounds().Size(); s.X > maxWidth || s.Y > maxHeight {
+		return imaging.Fit(entry.img, maxWidth, maxHeight, imaging.Box)
+	}
+	return entry.img
+}
@@ -0,0 +1,75 @@
+package cameraClient
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/vector"
+)
+
+// privacyMaskBlurFactor defines how much blurred regions are scaled down; higher values blur stronger.
+const privacyMaskBlurFactor = 16
+
+var privacyMaskSolid = image.NewUniform(color.Black)
+
+// PrivacyMask hides a region of the image.
+type PrivacyMask struct {
+	Polygon [][2]float64 // corners in percent of the image width and height
+	Blur    bool         // false: fill solid black
+}
+
+func (m PrivacyMask) cacheKey() string {
+	return fmt.Sprintf("%v-%t", m.Polygon, m.Blur)
+}
+
+// applyPrivacyMasks returns a copy of img with all masked regions filled or blurred.
+// Coordinates are relative to the image size such that masks work on images of any resolution.
+func applyPrivacyMasks(img image.Image, masks []PrivacyMask) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+
+	var blurred image.Image
+	for _, m := range masks {
+		src := image.Image(privacyMaskSolid)
+		if m.Blur {
+			if blurred == nil {
+				blurred = blurImage(img)
+			}
+			src = blurred
+		}
+		draw.DrawMask(dst, b, src, image.Point{}, polygonAlpha(b, m.Polygon), image.Point{}, draw.Over)
+	}
+
+	return dst
+}
+
+// blurImage scales the image down and up again, which blurs strong enough to hide faces and license plates.
+// The returned image has the bounds (0, 0)-(width, height).
+func blurImage(img image.Image) image.Image {
+	b := img.Bounds()
+	small := imaging.Resize(img, maxInt(1, b.Dx()/privacyMaskBlurFactor), maxInt(1, b.Dy()/privacyMaskBlurFactor), imaging.Box)
+	return imaging.Resize(small, b.Dx(), b.Dy(), imaging.Linear)
+}
+
+// polygonAlpha rasterizes the polygon given in percent into an alpha mask with the bounds (0, 0)-(width, height).
+func polygonAlpha(b image.Rectangle, polygon [][2]float64) *image.Alpha {
+	w, h := b.Dx(), b.Dy()
+	r := vector.NewRasterizer(w, h)
+	for i, p := range polygon {
+		x, y := float32(p[0]*float64(w)/100), float32(p[1]*float64(h)/100)
+		if i == 0 {
+			r.MoveTo(x, y)
+		} else {
+			r.LineTo(x, y)
+		}
+	}
+	r.ClosePath()
+
+	alpha := image.NewAlpha(image.Rect(0, 0, w, h))
+	r.Draw(alpha, alpha.Bounds(), image.Opaque, image.Point{})
+	return alpha
+}
@@ -0,0 +1,85 @@
+package cameraClient
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func testWhiteImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	return img
+}
+
+func gray(img image.Image, x, y int) uint8 {
+	return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+}
+
+func TestApplyPrivacyMasksSolid(t *testing.T) {
+	img := testWhiteImage(200, 100)
+	masked := applyPrivacyMasks(img, []PrivacyMask{
+		// left half
+		{Polygon: [][2]float64{{0, 0}, {50, 0}, {50, 100}, {0, 100}}},
+		// triangle in the upper right corner
+		{Polygon: [][2]float64{{75, 0}, {100, 0}, {100, 50}}},
+	})
+
+	tests := []struct {
+		x, y     int
+		expected uint8
+	}{
+		{10, 10, 0},
+		{99, 99, 0},
+		{110, 50, 255},
+		{195, 5, 0},
+		{155, 45, 255},
+	}
+	for _, tc := range tests {
+		if got := gray(masked, tc.x, tc.y); got != tc.expected {
+			t.Errorf("pixel %d,%d: expected %d, got %d", tc.x, tc.y, tc.expected, got)
+		}
+	}
+
+	if got := gray(img, 10, 10); got != 255 {
+		t.Errorf("expected the input image to be unchanged")
+	}
+}
+
+func TestApplyPrivacyMasksBlur(t *testing.T) {
+	// vertical stripes of 1px are blurred to an even gray
+	img := testWhiteImage(256, 64)
+	for x := 0; x < 256; x += 2 {
+		draw.Draw(img, image.Rect(x, 0, x+1, 64), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	}
+
+	masked := applyPrivacyMasks(img, []PrivacyMask{
+		{Polygon: [][2]float64{{0, 0}, {50, 0}, {50, 100}, {0, 100}}, Blur: true},
+	})
+
+	for _, x := range []int{40, 41} {
+		if got := gray(masked, x, 32); got < 64 || got > 192 {
+			t.Errorf("pixel %d,32: expected a blurred gray, got %d", x, got)
+		}
+	}
+	for _, x := range []int{200, 201} {
+		if got, expected := gray(masked, x, 32), gray(img, x, 32); got != expected {
+			t.Errorf("pixel %d,32: expected %d outside of the mask, got %d", x, expected, got)
+		}
+	}
+}
+
+func TestPrivacyMaskCacheKey(t *testing.T) {
+	polygon := [][2]float64{{0, 0}, {10, 0}, {10, 10}}
+	solid := PrivacyMask{Polygon: polygon}.cacheKey()
+	blur := PrivacyMask{Polygon: polygon, Blur: true}.cacheKey()
+	moved := PrivacyMask{Polygon: [][2]float64{{0, 0}, {20, 0}, {20, 10}}}.cacheKey()
+
+	if solid == blur || solid == moved {
+		t.Errorf("expected different cache keys, got %s, %s and %s", solid, blur, moved)
+	}
+	if solid != (PrivacyMask{Polygon: polygon}).cacheKey() {
+		t.Errorf("expected equal masks to have equal cache keys")
+	}
+}
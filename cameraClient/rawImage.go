@@ -5,19 +5,32 @@ import (
 	"image"
 	"image/jpeg"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type rawState struct {
-	readRequestChannel chan rawImageReadRequest
+	readRequestChannel         chan rawImageReadRequest
+	preemptiveRequestChannel   chan rawPreemptiveRequest
+	recentFramesRequestChannel chan rawRecentFramesRequest
 
 	// img image
 	img cameraPicture
+	// identifies the frame of img when the source is a frameSource
+	imgReceived time.Time
+
+	// the most recent frames including img
+	recentFrames frameRing
 
 	preemptiveTickerRunning bool
 	preemptiveTicker        *time.Ticker
+	preemptiveUntil         time.Time
+
+	// liveness check: start of the running fetch in unix nanoseconds, 0 while idle; accessed atomically
+	fetchStarted int64
+	ping         chan chan struct{}
 
 	// shutdown handling
 	shutdown chan struct{}
@@ -25,20 +38,34 @@ type rawState struct {
 }
 
 type rawImageReadRequest struct {
+	force    bool
 	response chan *cameraPicture
 }
 
-func createRawState() rawState {
+type rawPreemptiveRequest struct {
+	duration time.Duration
+}
+
+type rawRecentFramesRequest struct {
+	count    int
+	response chan []CameraPicture
+}
+
+func createRawState(recentFrames int) rawState {
 	// create a stopped ticker
 	ticker := time.NewTicker(time.Hour)
 	ticker.Stop()
 
 	return rawState{
-		readRequestChannel:      make(chan rawImageReadRequest, 16),
-		preemptiveTickerRunning: false,
-		preemptiveTicker:        ticker,
-		shutdown:                make(chan struct{}),
-		closed:                  make(chan struct{}),
+		readRequestChannel:         make(chan rawImageReadRequest, 16),
+		preemptiveRequestChannel:   make(chan rawPreemptiveRequest, 16),
+		recentFramesRequestChannel: make(chan rawRecentFramesRequest, 16),
+		recentFrames:               createFrameRing(recentFrames),
+		preemptiveTickerRunning:    false,
+		preemptiveTicker:           ticker,
+		ping:                       make(chan chan struct{}),
+		shutdown:                   make(chan struct{}),
+		closed:                     make(chan struct{}),
 	}
 }
 
@@ -62,6 +89,18 @@ func (c *Client) rawImageRoutine() {
 
 			// check if preemptive fetch needs to be started
 			c.startPreemptiveTicker()
+		case preemptiveRequest := <-c.raw.preemptiveRequestChannel:
+			if preemptiveRequest.duration > 0 {
+				c.raw.preemptiveUntil = time.Now().Add(preemptiveRequest.duration)
+				c.startPreemptiveTicker()
+			} else {
+				c.raw.preemptiveUntil = time.Time{}
+				c.stopPreemptiveTicker()
+			}
+		case recentFramesRequest := <-c.raw.recentFramesRequestChannel:
+			recentFramesRequest.response <- c.raw.recentFrames.last(recentFramesRequest.count)
+		case response := <-c.raw.ping:
+			close(response)
 		case <-c.raw.preemptiveTicker.C:
 			if cfg.LogDebug() {
 				log.Printf("cameraClient[%s]: preemptive fetch", c.Name())
@@ -70,7 +109,9 @@ func (c *Client) rawImageRoutine() {
 			c.fetchImage()
 
 			// check if preemptive fetch needs to be stopped
-			if lastFetch.Add(cfg.PreemptiveFetch()).Before(time.Now()) {
+			now := time.Now()
+			if lastFetch.Add(cfg.PreemptiveFetch()).Before(now) && c.raw.preemptiveUntil.Before(now) &&
+				!cfg.MotionDetection().Enabled() {
 				c.stopPreemptiveTicker()
 			}
 		case <-c.raw.shutdown:
@@ -81,7 +122,14 @@ func (c *Client) rawImageRoutine() {
 
 func (c *Client) isPreemptiveFetchEnabled() bool {
 	cfg := c.Config()
-	return cfg.RefreshInterval() > (50*time.Millisecond) && cfg.PreemptiveFetch() > cfg.RefreshInterval()
+	if cfg.RefreshInterval() <= (50 * time.Millisecond) {
+		return false
+	}
+	// motion detection needs a continuous stream of frames
+	if cfg.MotionDetection().Enabled() {
+		return true
+	}
+	return cfg.PreemptiveFetch() > cfg.RefreshInterval() || time.Now().Before(c.raw.preemptiveUntil)
 }
 
 func (c *Client) startPreemptiveTicker() {
@@ -101,6 +149,7 @@ func (c *Client) startPreemptiveTicker() {
 
 	c.raw.preemptiveTickerRunning = true
 	c.raw.preemptiveTicker.Reset(c.Config().RefreshInterval())
+	c.stats.preemptiveFetch(true)
 }
 
 func (c *Client) stopPreemptiveTicker() {
@@ -115,17 +164,27 @@ func (c *Client) stopPreemptiveTicker() {
 
 	c.raw.preemptiveTickerRunning = false
 	c.raw.preemptiveTicker.Stop()
+	c.stats.preemptiveFetch(false)
 }
 
 func (c *Client) handleRawImageReadRequest(request rawImageReadRequest) {
 	// fetch new image every RefreshInterval
-	if c.raw.img.Expired(-c.Config().ExpireEarly()) {
+	if request.force {
+		if c.Config().LogDebug() {
+			log.Printf("cameraClient[%s]: raw image forced fetch", c.Name())
+		}
+		c.fetchImage()
+	} else if c.raw.img.Expired(-c.Config().ExpireEarly()) {
 		if c.Config().LogDebug() {
 			log.Printf("cameraClient[%s]: raw image cache MISS", c.Name())
 		}
+		c.stats.cacheAccess(StageRaw, false, 1)
 		c.fetchImage()
-	} else if c.Config().LogDebug() {
-		log.Printf("cameraClient[%s]: raw image cache HIT, expiresIn=%s", c.Name(), time.Until(c.raw.img.expires))
+	} else {
+		if c.Config().LogDebug() {
+			log.Printf("cameraClient[%s]: raw image cache HIT, expiresIn=%s", c.Name(), time.Until(c.raw.img.expires))
+		}
+		c.stats.cacheAccess(StageRaw, true, 1)
 	}
 
 	request.response <- &c.raw.img
@@ -134,25 +193,58 @@ func (c *Client) handleRawImageReadRequest(request rawImageReadRequest) {
 func (c *Client) fetchImage() {
 	start0 := time.Now()
 
-	rawImg, err := c.rtspGetRawImage()
+	var rawImg []byte
+	var received time.Time
+	var err error
+	atomic.StoreInt64(&c.raw.fetchStarted, start0.UnixNano())
+	if fs, ok := c.source.(frameSource); ok {
+		rawImg, received, err = fs.GetRawFrame()
+	} else {
+		rawImg, err = c.source.GetRawImage()
+	}
+	atomic.StoreInt64(&c.raw.fetchStarted, 0)
 	if err != nil {
 		log.Printf("cameraClient[%s]: failed to fetch raw image: %v", c.Name(), err)
 	}
 
+	if err == nil && c.raw.img.err == nil && !received.IsZero() && received.Equal(c.raw.imgReceived) {
+		// no new frame was received since the last fetch; keep the image including its uuid such that
+		// consumers do not handle the same frame twice
+		c.raw.img.expires = time.Now().Add(c.Config().RefreshInterval())
+		if c.Config().LogDebug() {
+			log.Printf("cameraClient[%s]: raw image unchanged", c.Name())
+		}
+		return
+	}
+	c.raw.imgReceived = received
+
 	var decodedRawImg image.Image
 	if err == nil {
 		decodedRawImg, err = jpeg.Decode(bytes.NewReader(rawImg))
 	}
 
+	// transform once such that all views, sizes and consumers of the jpg image get the corrected image
+	if transform := c.Config().Transform(); err == nil && transformEnabled(transform) {
+		decodedRawImg = transformImage(decodedRawImg, transform)
+		rawImg, err = encodeImage(decodedRawImg, ImageFormatJpg, transformJpgQuality)
+	}
+
 	now := time.Now()
 	c.raw.img = cameraPicture{
-		jpgImg:     rawImg,
-		decodedImg: decodedRawImg,
-		fetched:    now,
-		expires:    now.Add(c.Config().RefreshInterval()),
-		uuid:       uuid.New().String(),
-		err:        err,
+		jpgImg:        rawImg,
+		decodedImg:    decodedRawImg,
+		fetched:       now,
+		fetchDuration: now.Sub(start0),
+		expires:       now.Add(c.Config().RefreshInterval()),
+		uuid:          uuid.New().String(),
+		err:           err,
+	}
+	c.stats.fetched(&c.raw.img)
+	if err == nil {
+		c.raw.recentFrames.push(c.raw.img)
 	}
+	c.publishRawImage(c.raw.img)
+	c.detectMotion(decodedRawImg, now)
 
 	if c.Config().LogDebug() && decodedRawImg != nil {
 		log.Printf(
@@ -0,0 +1,34 @@
+package cameraClient
+
+// frameRing keeps the most recent successfully fetched raw frames of a camera.
+// Only the jpg images are kept; the decoded images would use a multiple of the memory.
+type frameRing struct {
+	frames []cameraPicture
+	next   int // index the next frame is written to
+	count  int
+}
+
+func createFrameRing(size int) frameRing {
+	return frameRing{frames: make([]cameraPicture, size)}
+}
+
+func (r *frameRing) push(cp cameraPicture) {
+	if len(r.frames) < 1 {
+		return
+	}
+	cp.decodedImg = nil
+	r.frames[r.next] = cp
+	r.next = (r.next + 1) % len(r.frames)
+	r.count = minInt(r.count+1, len(r.frames))
+}
+
+// last returns up to n of the most recent frames, the oldest first.
+func (r *frameRing) last(n int) []CameraPicture {
+	n = minInt(n, r.count)
+	ret := make([]CameraPicture, 0, maxInt(n, 0))
+	for i := n; i > 0; i-- {
+		idx := (r.next - i + len(r.frames)) % len(r.frames)
+		ret = append(ret, r.frames[idx])
+	}
+	return ret
+}
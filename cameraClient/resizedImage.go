@@ -1,8 +1,6 @@
-
 package cameraClient
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"github.com/disintegration/imaging"
@@ -14,11 +12,15 @@ import (
 
 type resizeState struct {
 	readRequestChannel chan resizedImageReadRequest
+	invalidateChannel  chan struct{}
 
 	cache                  cameraPictureMap
 	computeResponseChannel chan resizedImageComputeResponse
 	waitingResponses       map[string][]chan *cameraPicture
 
+	// liveness check
+	ping chan chan struct{}
+
 	// shutdown handling
 	shutdown chan struct{}
 	closed   chan struct{}
@@ -28,6 +30,7 @@ type resizedImageRequest struct {
 	refreshInterval time.Duration
 	dim             Dimension
 	jpgQuality      int
+	options         ImageOptions
 }
 
 type resizedImageReadRequest struct {
@@ -43,9 +46,11 @@ type resizedImageComputeResponse struct {
 func createResizeState() resizeState {
 	return resizeState{
 		readRequestChannel:     make(chan resizedImageReadRequest, 16),
+		invalidateChannel:      make(chan struct{}, 16),
 		cache:                  make(cameraPictureMap),
 		computeResponseChannel: make(chan resizedImageComputeResponse, 16),
 		waitingResponses:       make(map[string][]chan *cameraPicture),
+		ping:                   make(chan chan struct{}),
 		shutdown:               make(chan struct{}),
 		closed:                 make(chan struct{}),
 	}
@@ -57,8 +62,13 @@ func (c *Client) resizedImageRoutine() {
 		select {
 		case readRequest := <-c.resize.readRequestChannel:
 			c.handleResizedImageReadRequest(readRequest)
+		case <-c.resize.invalidateChannel:
+			c.resize.cache = make(cameraPictureMap)
+			c.stats.cacheEntries(StageResize, 0)
 		case computeResponse := <-c.resize.computeResponseChannel:
 			c.handleResizeComputeResponse(computeResponse)
+		case response := <-c.resize.ping:
+			close(response)
 		case <-c.resize.shutdown:
 			return
 		}
@@ -75,11 +85,13 @@ func (c *Client) handleResizedImageReadRequest(request resizedImageReadRequest)
 				c.Name(), cacheKey, time.Until(cp.expires),
 			)
 		}
+		c.stats.cacheAccess(StageResize, true, len(c.resize.cache))
 		request.response <- cp
 	} else {
 		if c.Config().LogDebug() {
 			log.Printf("cameraClient[%s]: resize image cache MISS, cacheKey=%s", c.Name(), cacheKey)
 		}
+		c.stats.cacheAccess(StageResize, false, len(c.resize.cache))
 		if responses, ok := c.resize.waitingResponses[cacheKey]; ok {
 			if c.Config().LogDebug() {
 				log.Printf("cameraClient[%s]: waitingResponses HIT, cacheKey=%s", c.Name(), cacheKey)
@@ -104,6 +116,7 @@ func (c *Client) handleResizeComputeResponse(response resizedImageComputeRespons
 
 	// add new image to cache
 	c.resize.cache[response.cacheKey] = response.resizedImage
+	c.stats.cacheEntries(StageResize, len(c.resize.cache))
 }
 
 func (c *Client) resizeOperation(request resizedImageRequest) {
@@ -112,22 +125,30 @@ func (c *Client) resizeOperation(request resizedImageRequest) {
 
 	start1 := time.Now()
 
-	var oupJpgImg []byte
+	var oupImg []byte
 	var oupDecodedImg image.Image
 	err := delayedImg.Err()
 	if err == nil {
-		oupJpgImg, oupDecodedImg, err = imageResize(
+		oupImg, oupDecodedImg, err = imageResize(
 			delayedImg.JpgImg(), delayedImg.DecodedImg(), request.dim, request.jpgQuality,
+			request.options, delayedImg.Fetched(),
 		)
 	}
 
 	resizedImage := &cameraPicture{
-		jpgImg:     oupJpgImg,
-		decodedImg: oupDecodedImg,
-		fetched:    delayedImg.Fetched(),
-		expires:    delayedImg.Expires(),
-		uuid:       delayedImg.Uuid(),
-		err:        err,
+		decodedImg:    oupDecodedImg,
+		fetched:       delayedImg.Fetched(),
+		fetchDuration: delayedImg.FetchDuration(),
+		expires:       delayedImg.Expires(),
+		uuid:          delayedImg.Uuid(),
+		variant:       request.options.CacheKey(),
+		format:        request.options.format(),
+		err:           err,
+	}
+	if resizedImage.format == ImageFormatJpg {
+		resizedImage.jpgImg = oupImg
+	} else {
+		resizedImage.encodedImg = oupImg
 	}
 
 	if c.Config().LogDebug() {
@@ -139,29 +160,55 @@ func (c *Client) resizeOperation(request resizedImageRequest) {
 		)
 	}
 
-	c.resize.computeResponseChannel <- resizedImageComputeResponse{
+	select {
+	case c.resize.computeResponseChannel <- resizedImageComputeResponse{
 		request.computeCacheKey(),
 		resizedImage,
+	}:
+	case <-c.resize.shutdown:
 	}
 }
 
 func (request resizedImageRequest) computeCacheKey() string {
 	return fmt.Sprintf(
-		"%s-%s-%d",
+		"%s-%s-%d-%s",
 		request.refreshInterval.String(),
 		DimensionCacheKey(request.dim),
 		request.jpgQuality,
+		request.options.CacheKey(),
 	)
 }
 
+// imageResize crops the image, scales it down to fit into requestedDim, applies the remaining options
+// and returns the image encoded in the format given by the options.
 func imageResize(
 	inpJpgImg []byte, inpDecodedImg image.Image, requestedDim Dimension, jpgQuality int,
-) (oupJpgImg []byte, oupDecodedImg image.Image, err error) {
+	options ImageOptions, fetched time.Time,
+) (oupImg []byte, oupDecodedImg image.Image, err error) {
 	if inpDecodedImg == nil {
+		if options.format() != ImageFormatJpg {
+			return nil, nil, fmt.Errorf("cannot encode %s: image is not decoded", options.format())
+		}
 		return inpJpgImg, inpDecodedImg, nil
 	}
 
-	inpDim := DimensionOfImage(inpDecodedImg)
+	resizedImg := processImage(inpDecodedImg, requestedDim, options, fetched)
+
+	oupImg, err = encodeImage(resizedImg, options.format(), jpgQuality)
+	if err != nil {
+		return
+	}
+
+	return oupImg, resizedImg, nil
+}
+
+// processImage crops the image, scales it down to fit into requestedDim and applies the remaining options.
+func processImage(img image.Image, requestedDim Dimension, options ImageOptions, fetched time.Time) image.Image {
+	if options.Crop != nil {
+		img = options.Crop.crop(img)
+	}
+
+	inpDim := DimensionOfImage(img)
 	var width, height int
 	if requestedDim.Width()*inpDim.Height()/inpDim.Width() < requestedDim.Height() {
 		width = minInt(inpDim.Width(), requestedDim.Width())
@@ -171,23 +218,11 @@ func imageResize(
 		height = minInt(inpDim.Height(), requestedDim.Height())
 	}
 
-	var resizedImg image.Image
-	if inpDim.Width() == width || inpDim.Height() == height {
-		resizedImg = inpDecodedImg
-	} else {
-		resizedImg = imaging.Resize(inpDecodedImg, width, height, imaging.Box)
-	}
-
-	var b bytes.Buffer
-	w := bufio.NewWriter(&b)
-
-	err = jpeg.Encode(w, resizedImg, &jpeg.Options{Quality: jpgQuality})
-
-	if err != nil {
-		return
+	if inpDim.Width() != width && inpDim.Height() != height {
+		img = imaging.Resize(img, width, height, imaging.Box)
 	}
 
-	return b.Bytes(), resizedImg, nil
+	return options.apply(img, fetched)
 }
 
 func minInt(x, y int) int {
@@ -196,3 +231,16 @@ func minInt(x, y int) int {
 	}
 	return y
 }
+
+// ResizeJpg decodes the given jpg image, scales it down to fit into dim and applies the options.
+// fetched is the time the image was taken; it is used by the overlay timestamp.
+// The output is always a jpg image; the format of the options is ignored.
+func ResizeJpg(jpgImg []byte, dim Dimension, jpgQuality int, options ImageOptions, fetched time.Time) ([]byte, error) {
+	options.Format = ImageFormatJpg
+	decodedImg, err := jpeg.Decode(bytes.NewReader(jpgImg))
+	if err != nil {
+		return nil, err
+	}
+	oupJpgImg, _, err := imageResize(jpgImg, decodedImg, dim, jpgQuality, options, fetched)
+	return oupJpgImg, err
+}
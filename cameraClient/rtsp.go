@@ -1,37 +1,40 @@
 package cameraClient
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 )
 
-type rtspState struct {
+type rtspSource struct {
+	config Config
 	tmpDir string
 }
 
-func createRtspState() rtspState {
+func createRtspSource(config Config) (*rtspSource, error) {
 	tmpDir, err := os.MkdirTemp("", "go-webcam")
 	if err != nil {
-		log.Fatalf("failed to create rtspState tmpDir: %v", err)
+		return nil, fmt.Errorf("failed to create rtspSource tmpDir: %v", err)
 	}
 
-	return rtspState{
+	return &rtspSource{
+		config: config,
 		tmpDir: tmpDir,
-	}
+	}, nil
 }
 
-func (rs *rtspState) Close() {
+func (rs *rtspSource) Close() {
 	err := os.RemoveAll(rs.tmpDir)
 	if err != nil {
-		log.Printf("failed to remove rtspState tmpDir=%s: %v", rs.tmpDir, err)
+		log.Printf("failed to remove rtspSource tmpDir=%s: %v", rs.tmpDir, err)
 	}
 }
 
-func (c *Client) rtspGetRawImage() (img []byte, err error) {
-	url := c.config.Address()
-	outputFile := filepath.Join(c.rtsp.tmpDir, "tmp.jpg")
+func (rs *rtspSource) GetRawImage() (img []byte, err error) {
+	url := rs.config.Address()
+	outputFile := filepath.Join(rs.tmpDir, "tmp.jpg")
 
 	cmd := exec.Command("ffmpeg",
 		"-y",
@@ -0,0 +1,238 @@
+package cameraClient
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	rtspStreamBackoffMin = time.Second
+	rtspStreamBackoffMax = 30 * time.Second
+
+	// rtspStreamStartupTimeout is the minimum time given to connect and to receive the first keyframe
+	rtspStreamStartupTimeout = 30 * time.Second
+)
+
+// rtspStreamSource keeps one ffmpeg process per camera running which writes mjpeg frames
+// to a pipe. GetRawImage always returns the newest frame received.
+type rtspStreamSource struct {
+	config Config
+
+	frameMutex sync.Mutex
+	frameCond  *sync.Cond
+	frame      []byte
+	frameTime  time.Time
+	lastErr    error
+
+	cancel context.CancelFunc
+	closed chan struct{}
+}
+
+func createRtspStreamSource(config Config) (*rtspStreamSource, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rs := &rtspStreamSource{
+		config: config,
+		cancel: cancel,
+		closed: make(chan struct{}),
+	}
+	rs.frameCond = sync.NewCond(&rs.frameMutex)
+
+	go rs.streamRoutine(ctx)
+
+	return rs, nil
+}
+
+func (rs *rtspStreamSource) Close() {
+	rs.cancel()
+	<-rs.closed
+
+	// wake up waiting readers
+	rs.frameCond.Broadcast()
+}
+
+func (rs *rtspStreamSource) GetRawImage() (img []byte, err error) {
+	img, _, err = rs.GetRawFrame()
+	return
+}
+
+// GetRawFrame returns the newest frame and the time it was received; a frame is returned until the next one arrives.
+func (rs *rtspStreamSource) GetRawFrame() (img []byte, received time.Time, err error) {
+	rs.frameMutex.Lock()
+	defer rs.frameMutex.Unlock()
+
+	if rs.frame == nil {
+		// wait for the first frame, at most for the configured timeout
+		timer := time.AfterFunc(rs.config.Timeout(), rs.frameCond.Broadcast)
+		defer timer.Stop()
+		rs.frameCond.Wait()
+	}
+
+	if rs.frame == nil {
+		if rs.lastErr != nil {
+			return nil, time.Time{}, fmt.Errorf("no frame received yet: %s", rs.lastErr)
+		}
+		return nil, time.Time{}, fmt.Errorf("no frame received yet")
+	}
+
+	if age := time.Since(rs.frameTime); age > rs.config.Timeout() {
+		return nil, time.Time{}, fmt.Errorf("stream is stale, newest frame is %.1fs old: %v", age.Seconds(), rs.lastErr)
+	}
+
+	return rs.frame, rs.frameTime, nil
+}
+
+func (rs *rtspStreamSource) streamRoutine(ctx context.Context) {
+	defer close(rs.closed)
+
+	backoff := rtspStreamBackoffMin
+	for {
+		start := time.Now()
+		err := rs.runStream(ctx)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		rs.frameMutex.Lock()
+		rs.lastErr = err
+		rs.frameMutex.Unlock()
+
+		// reset backoff when the stream was running for a while
+		if time.Since(start) > rtspStreamBackoffMax {
+			backoff = rtspStreamBackoffMin
+		}
+
+		log.Printf("cameraClient[%s]: rtsp stream stopped: %v; reconnect in %s", rs.config.Name(), err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > rtspStreamBackoffMax {
+			backoff = rtspStreamBackoffMax
+		}
+	}
+}
+
+func (rs *rtspStreamSource) runStream(ctx context.Context) error {
+	args := []string{
+		"-threads", "1",
+		"-rtsp_transport", "tcp",
+		"-i", rs.config.Address(),
+	}
+	if refreshInterval := rs.config.RefreshInterval(); refreshInterval > 0 {
+		// only decode / encode as many frames as are needed
+		fps := float64(time.Second) / float64(refreshInterval)
+		args = append(args, "-r", strconv.FormatFloat(fps, 'f', 3, 64))
+	}
+	args = append(args,
+		"-f", "image2pipe",
+		"-c:v", "mjpeg",
+		"-q:v", "2",
+		"-",
+	)
+
+	// a stream which stalls without terminating ffmpeg (eg. when the camera drops off the network)
+	// is killed by the watchdog when no frame is received within the timeout; it is then reconnected.
+	// Connecting and waiting for the first keyframe gets at least rtspStreamStartupTimeout.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var stalled atomic.Bool
+	watchdogTimeout := max(rs.config.Timeout(), rtspStreamStartupTimeout)
+	watchdog := time.AfterFunc(watchdogTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer watchdog.Stop()
+
+	cmd := exec.CommandContext(runCtx, "ffmpeg", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if rs.config.LogDebug() {
+		log.Printf("cameraClient[%s]: rtsp stream started", rs.config.Name())
+	}
+
+	reader := bufio.NewReaderSize(stdout, 1<<16)
+	var readErr error
+	for {
+		frame, err := readJpegFrame(reader)
+		if err != nil {
+			readErr = err
+			break
+		}
+		watchdogTimeout = rs.config.Timeout()
+		watchdog.Reset(watchdogTimeout)
+
+		rs.frameMutex.Lock()
+		rs.frame = frame
+		rs.frameTime = time.Now()
+		rs.lastErr = nil
+		rs.frameMutex.Unlock()
+		rs.frameCond.Broadcast()
+	}
+
+	waitErr := cmd.Wait()
+	if stalled.Load() {
+		return fmt.Errorf("no frame received within %s", watchdogTimeout)
+	}
+	if waitErr != nil {
+		return waitErr
+	}
+	if readErr == io.EOF {
+		return fmt.Errorf("ffmpeg terminated")
+	}
+	return readErr
+}
+
+// readJpegFrame returns the next jpeg image (from start of image to end of image marker) from the reader.
+func readJpegFrame(r *bufio.Reader) ([]byte, error) {
+	// skip everything up to the start of image marker
+	for {
+		if _, err := r.ReadBytes(0xFF); err != nil {
+			return nil, err
+		}
+		b, err := r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] == 0xD8 {
+			_, _ = r.Discard(1)
+			break
+		}
+	}
+
+	// collect everything up to the end of image marker
+	frame := []byte{0xFF, 0xD8}
+	for {
+		chunk, err := r.ReadBytes(0xFF)
+		if err != nil {
+			return nil, err
+		}
+		frame = append(frame, chunk...)
+		b, err := r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] == 0xD9 {
+			_, _ = r.Discard(1)
+			return append(frame, 0xD9), nil
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package cameraClient
+
+import (
+	"sync"
+	"time"
+)
+
+// FetchDurationBuckets are the upper bounds in seconds of the fetch duration histogram in Stats.
+var FetchDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+const (
+	StageRaw     = "raw"
+	StageDelayed = "delayed"
+	StageResize  = "resize"
+)
+
+var Stages = []string{StageRaw, StageDelayed, StageResize}
+
+// Stats is a snapshot of the counters of a client.
+type Stats struct {
+	FetchCount           uint64
+	FetchErrors          uint64
+	ConsecutiveFailures  uint64
+	FetchDurationSum     time.Duration
+	FetchDurationBuckets []uint64 // number of fetches per bucket of FetchDurationBuckets, not cumulative
+	LastFetch            time.Time
+	LastSuccess          time.Time
+	LastSuccessDimension Dimension
+	LastFailure          time.Time
+	LastErr              error
+
+	PreemptiveFetchRunning bool
+
+	MotionActive    bool
+	MotionEvents    uint64 // number of times motion started
+	LastMotionStart time.Time
+	LastMotionEnd   time.Time
+	LastMotionArea  float64 // largest percentage of changed cells of the current or last motion
+
+	CacheHits    map[string]uint64 // per stage
+	CacheMisses  map[string]uint64 // per stage
+	CacheEntries map[string]int    // per stage
+}
+
+type stats struct {
+	mutex sync.Mutex
+	data  Stats
+}
+
+func createStats() *stats {
+	return &stats{
+		data: Stats{
+			FetchDurationBuckets: make([]uint64, len(FetchDurationBuckets)+1),
+			LastSuccessDimension: dimension{0, 0},
+			CacheHits:            make(map[string]uint64, len(Stages)),
+			CacheMisses:          make(map[string]uint64, len(Stages)),
+			CacheEntries:         make(map[string]int, len(Stages)),
+		},
+	}
+}
+
+func (s *stats) fetched(cp *cameraPicture) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data.FetchCount += 1
+	s.data.LastFetch = cp.fetched
+	s.data.FetchDurationSum += cp.fetchDuration
+
+	seconds := cp.fetchDuration.Seconds()
+	bucket := len(FetchDurationBuckets)
+	for i, upperBound := range FetchDurationBuckets {
+		if seconds <= upperBound {
+			bucket = i
+			break
+		}
+	}
+	s.data.FetchDurationBuckets[bucket] += 1
+
+	if cp.err != nil {
+		s.data.FetchErrors += 1
+		s.data.ConsecutiveFailures += 1
+		s.data.LastFailure = cp.fetched
+		s.data.LastErr = cp.err
+	} else {
+		s.data.ConsecutiveFailures = 0
+		s.data.LastSuccess = cp.fetched
+		s.data.LastSuccessDimension = cp.Dimension()
+	}
+}
+
+func (s *stats) cacheAccess(stage string, hit bool, entries int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if hit {
+		s.data.CacheHits[stage] += 1
+	} else {
+		s.data.CacheMisses[stage] += 1
+	}
+	s.data.CacheEntries[stage] = entries
+}
+
+func (s *stats) cacheEntries(stage string, entries int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.CacheEntries[stage] = entries
+}
+
+func (s *stats) preemptiveFetch(running bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.PreemptiveFetchRunning = running
+}
+
+func (s *stats) motion(event MotionEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if event.Active && !s.data.MotionActive {
+		s.data.MotionEvents += 1
+	}
+	s.data.MotionActive = event.Active
+	s.data.LastMotionStart = event.Start
+	s.data.LastMotionEnd = event.End
+	s.data.LastMotionArea = event.Area
+}
+
+func (s *stats) snapshot() (ret Stats) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret = s.data
+	ret.FetchDurationBuckets = append([]uint64(nil), s.data.FetchDurationBuckets...)
+	ret.CacheHits = copyMap(s.data.CacheHits)
+	ret.CacheMisses = copyMap(s.data.CacheMisses)
+	ret.CacheEntries = copyMap(s.data.CacheEntries)
+	return
+}
+
+func copyMap[V any](m map[string]V) map[string]V {
+	ret := make(map[string]V, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
+
+// Stats returns a snapshot of the fetch and cache counters of this client.
+func (c *Client) Stats() Stats {
+	return c.stats.snapshot()
+}
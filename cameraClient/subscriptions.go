@@ -0,0 +1,51 @@
+package cameraClient
+
+import "sync"
+
+type subscriptions[T any] struct {
+	mutex    sync.Mutex
+	channels map[chan T]struct{}
+}
+
+func createSubscriptions[T any]() subscriptions[T] {
+	return subscriptions[T]{
+		channels: make(map[chan T]struct{}),
+	}
+}
+
+func (s *subscriptions[T]) subscribe(bufferSize int) (<-chan T, func()) {
+	ch := make(chan T, bufferSize)
+
+	s.mutex.Lock()
+	s.channels[ch] = struct{}{}
+	s.mutex.Unlock()
+
+	return ch, func() {
+		s.mutex.Lock()
+		delete(s.channels, ch)
+		s.mutex.Unlock()
+	}
+}
+
+func (s *subscriptions[T]) publish(v T) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for ch := range s.channels {
+		select {
+		case ch <- v:
+		default:
+			// receiver is busy, drop the value
+		}
+	}
+}
+
+// SubscribeRawImages returns a channel receiving every newly fetched raw image including failed fetches.
+// Images are dropped when the receiver is not ready. unsubscribe must be called when done.
+func (c *Client) SubscribeRawImages() (images <-chan CameraPicture, unsubscribe func()) {
+	return c.subscriptions.subscribe(1)
+}
+
+func (c *Client) publishRawImage(cp CameraPicture) {
+	c.subscriptions.publish(cp)
+}
@@ -0,0 +1,127 @@
+package cameraClient
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSource returns the given image or error on every fetch.
+type testSource struct {
+	mutex sync.Mutex
+	img   []byte
+	err   error
+}
+
+func (s *testSource) GetRawImage() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.img, s.err
+}
+
+func (s *testSource) Close() {}
+
+func (s *testSource) set(img []byte, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.img, s.err = img, err
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+	}
+	panic("unreachable")
+}
+
+func TestSubscriptions(t *testing.T) {
+	s := createSubscriptions[int]()
+
+	a, unsubscribeA := s.subscribe(1)
+	b, unsubscribeB := s.subscribe(1)
+	defer unsubscribeB()
+
+	s.publish(1)
+	if got := receive(t, a); got != 1 {
+		t.Errorf("a: expected 1, got %d", got)
+	}
+
+	// b did not receive yet; the second value is dropped instead of blocking the publisher
+	s.publish(2)
+	if got := receive(t, b); got != 1 {
+		t.Errorf("b: expected 1, got %d", got)
+	}
+	if got := receive(t, a); got != 2 {
+		t.Errorf("a: expected 2, got %d", got)
+	}
+
+	unsubscribeA()
+	s.publish(3)
+	select {
+	case v := <-a:
+		t.Errorf("a: received %d after unsubscribe", v)
+	default:
+	}
+	if got := receive(t, b); got != 3 {
+		t.Errorf("b: expected 3, got %d", got)
+	}
+}
+
+func TestSubscribeRawImages(t *testing.T) {
+	source := &testSource{img: testJpeg(t)}
+	client := RunClientWithSource(&testConfig{
+		name:            "cam-sub",
+		timeout:         time.Second,
+		refreshInterval: time.Minute,
+		recentFrames:    2,
+	}, source)
+	defer client.Shutdown()
+
+	images, unsubscribe := client.SubscribeRawImages()
+	defer unsubscribe()
+
+	fetched := client.FetchImage()
+	if fetched.Err() != nil {
+		t.Fatalf("unexpected error: %s", fetched.Err())
+	}
+	// the returned picture is the cache entry of the raw image routine; it is replaced by the next fetch
+	fetchedUuid := fetched.Uuid()
+	if got := receive(t, images); got.Uuid() != fetchedUuid {
+		t.Errorf("expected the fetched image %s, got %s", fetchedUuid, got.Uuid())
+	}
+
+	// failed fetches are published as well
+	source.set(nil, errors.New("camera offline"))
+	client.FetchImage()
+	if got := receive(t, images); got.Err() == nil {
+		t.Errorf("expected a failed image")
+	}
+
+	// only successful fetches are kept as recent frames
+	if got := client.GetRecentFrames(5); len(got) != 1 || got[0].Uuid() != fetchedUuid {
+		t.Errorf("expected the first image as the only recent frame, got %d frames", len(got))
+	}
+}
+
+func TestClientShutdown(t *testing.T) {
+	client := RunClientWithSource(&testConfig{
+		name:            "cam-shutdown",
+		timeout:         time.Second,
+		refreshInterval: time.Minute,
+	}, &testSource{img: testJpeg(t)})
+	client.Shutdown()
+
+	select {
+	case <-client.Done():
+	default:
+		t.Errorf("expected Done to be closed")
+	}
+	if cp := client.GetDelayedImage(time.Second); !errors.Is(cp.Err(), ErrShutdown) {
+		t.Errorf("expected ErrShutdown, got %v", cp.Err())
+	}
+}
@@ -0,0 +1,95 @@
+package cameraClient
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"time"
+)
+
+// testPatternSource renders color bars plus the camera name, the current time and a frame counter.
+type testPatternSource struct {
+	config     Config
+	frameCount uint64
+}
+
+var testPatternColors = []color.RGBA{
+	{192, 192, 192, 255}, // gray
+	{192, 192, 0, 255},   // yellow
+	{0, 192, 192, 255},   // cyan
+	{0, 192, 0, 255},     // green
+	{192, 0, 192, 255},   // magenta
+	{192, 0, 0, 255},     // red
+	{0, 0, 192, 255},     // blue
+}
+
+func createTestPatternSource(config Config) (*testPatternSource, error) {
+	return &testPatternSource{
+		config: config,
+	}, nil
+}
+
+func (ts *testPatternSource) Close() {}
+
+func (ts *testPatternSource) GetRawImage() (img []byte, err error) {
+	ts.frameCount += 1
+
+	var b bytes.Buffer
+	err = jpeg.Encode(&b, ts.render(time.Now()), &jpeg.Options{Quality: 90})
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (ts *testPatternSource) render(now time.Time) *image.RGBA {
+	width := ts.config.ResolutionWidth()
+	height := ts.config.ResolutionHeight()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// vertical color bars over the upper 2/3
+	barsHeight := height * 2 / 3
+	for i, c := range testPatternColors {
+		bar := image.Rect(i*width/len(testPatternColors), 0, (i+1)*width/len(testPatternColors), barsHeight)
+		draw.Draw(img, bar, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	// gray ramp below
+	for x := 0; x < width; x++ {
+		v := uint8(x * 255 / maxInt(width-1, 1))
+		draw.Draw(img, image.Rect(x, barsHeight, x+1, height), image.NewUniform(color.Gray{Y: v}), image.Point{}, draw.Src)
+	}
+
+	// a marker moving by one step per frame, makes frozen images easy to spot
+	markerSize := maxInt(height/20, 2)
+	markerX := int(ts.frameCount*uint64(markerSize)) % maxInt(width-markerSize, 1)
+	draw.Draw(img,
+		image.Rect(markerX, barsHeight-markerSize, markerX+markerSize, barsHeight),
+		image.NewUniform(color.White), image.Point{}, draw.Src,
+	)
+
+	// text lines
+	fontSize := maxInt(height/16, 8)
+	pt := image.Point{X: fontSize / 2, Y: fontSize / 2}
+	for _, line := range []string{
+		ts.config.Name(),
+		now.Format("2006-01-02 15:04:05.000"),
+		fmt.Sprintf("frame %d", ts.frameCount),
+		fmt.Sprintf("%dx%d", width, height),
+	} {
+		box := drawTextBox(img, pt, fontSize, line, color.White, color.RGBA{0, 0, 0, 192})
+		pt.Y = box.Max.Y + fontSize/4
+	}
+
+	return img
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
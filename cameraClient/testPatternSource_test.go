@@ -0,0 +1,48 @@
+package cameraClient
+
+import (
+	"bytes"
+	"image/jpeg"
+	"testing"
+)
+
+func TestTestPatternSource(t *testing.T) {
+	ts, err := createTestPatternSource(&testConfig{name: "cam-test", width: 320, height: 180})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := ts.GetRawImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(first))
+	if err != nil {
+		t.Fatalf("cannot decode image: %s", err)
+	}
+	if got := img.Bounds().Size(); got.X != 320 || got.Y != 180 {
+		t.Errorf("expected 320x180, got %dx%d", got.X, got.Y)
+	}
+
+	// the moving marker and the frame counter change every frame
+	second, err := ts.GetRawImage()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if bytes.Equal(first, second) {
+		t.Errorf("expected consecutive frames to differ")
+	}
+	if ts.frameCount != 2 {
+		t.Errorf("expected frame count 2, got %d", ts.frameCount)
+	}
+}
+
+func TestTestPatternSourceTinyResolution(t *testing.T) {
+	ts, err := createTestPatternSource(&testConfig{name: "cam-test", width: 1, height: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.GetRawImage(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,88 @@
+package cameraClient
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	textFont      *opentype.Font
+	textFontOnce  sync.Once
+	textFaceCache sync.Map // font size in pixels -> *textFaceEntry
+)
+
+// font faces are not safe for concurrent use, hence one face per size guarded by a mutex
+type textFaceEntry struct {
+	mutex sync.Mutex
+	face  font.Face
+}
+
+func getTextFace(size int) *textFaceEntry {
+	if entry, ok := textFaceCache.Load(size); ok {
+		return entry.(*textFaceEntry)
+	}
+
+	textFontOnce.Do(func() {
+		var err error
+		if textFont, err = opentype.Parse(gomonobold.TTF); err != nil {
+			panic(err)
+		}
+	})
+
+	face, err := opentype.NewFace(textFont, &opentype.FaceOptions{
+		Size:    float64(size),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	entry, _ := textFaceCache.LoadOrStore(size, &textFaceEntry{face: face})
+	return entry.(*textFaceEntry)
+}
+
+// textBoxSize returns the size of the box drawn by drawTextBox.
+func textBoxSize(size int, text string) image.Point {
+	entry := getTextFace(size)
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	padding := size / 4
+	metrics := entry.face.Metrics()
+	return image.Point{
+		X: font.MeasureString(entry.face, text).Ceil() + 2*padding,
+		Y: (metrics.Ascent + metrics.Descent).Ceil() + 2*padding,
+	}
+}
+
+// drawTextBox draws a single line of text with a font size of size pixels onto dst.
+// pt is the top left corner of the box around the text which is filled with bg unless bg is nil.
+func drawTextBox(dst draw.Image, pt image.Point, size int, text string, fg, bg color.Color) image.Rectangle {
+	box := image.Rectangle{Min: pt, Max: pt.Add(textBoxSize(size, text))}
+	if bg != nil {
+		draw.Draw(dst, box, image.NewUniform(bg), image.Point{}, draw.Over)
+	}
+
+	entry := getTextFace(size)
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	padding := size / 4
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(fg),
+		Face: entry.face,
+		Dot:  fixed.P(pt.X+padding, pt.Y+padding+entry.face.Metrics().Ascent.Ceil()),
+	}
+	d.DrawString(text)
+
+	return box
+}
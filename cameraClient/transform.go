@@ -0,0 +1,96 @@
+package cameraClient
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// transformJpgQuality is used to encode raw images again after they were transformed.
+const transformJpgQuality = 95
+
+type TransformConfig interface {
+	Rotate() int             // clockwise in degrees: 0, 90, 180 or 270
+	Flip() string            // none, horizontal or vertical
+	LensCorrection() float64 // 0: disabled
+}
+
+func transformEnabled(cfg TransformConfig) bool {
+	return cfg.Rotate() != 0 || (cfg.Flip() != "" && cfg.Flip() != "none") || cfg.LensCorrection() != 0
+}
+
+// transformImage corrects the lens distortion and then rotates and flips the image.
+func transformImage(img image.Image, cfg TransformConfig) image.Image {
+	if k := cfg.LensCorrection(); k != 0 {
+		img = correctLens(img, k)
+	}
+
+	switch cfg.Rotate() {
+	case 90:
+		img = imaging.Rotate270(img) // imaging rotates counter-clockwise
+	case 180:
+		img = imaging.Rotate180(img)
+	case 270:
+		img = imaging.Rotate90(img)
+	}
+
+	switch cfg.Flip() {
+	case "horizontal":
+		img = imaging.FlipH(img)
+	case "vertical":
+		img = imaging.FlipV(img)
+	}
+
+	return img
+}
+
+// correctLens applies a simple radial distortion model: every output pixel at the distance r from the center
+// is taken from the distance r * (1 - k * r²) of the input, where r is relative to half of the image diagonal.
+// Positive k straightens lines bent outwards by wide angle lenses (barrel distortion); the corners are cropped.
+// Negative k corrects pincushion distortion.
+func correctLens(img image.Image, k float64) image.Image {
+	src := imaging.Clone(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	cx, cy := float64(w-1)/2, float64(h-1)/2
+	norm := cx*cx + cy*cy
+	if norm <= 0 {
+		return src
+	}
+
+	for y := 0; y < h; y++ {
+		dy := float64(y) - cy
+		for x := 0; x < w; x++ {
+			dx := float64(x) - cx
+			f := 1 - k*(dx*dx+dy*dy)/norm
+			sampleBilinear(src, dst.Pix[y*dst.Stride+4*x:y*dst.Stride+4*x+4], cx+dx*f, cy+dy*f)
+		}
+	}
+
+	return dst
+}
+
+// sampleBilinear writes the interpolated color of src at (x, y) to the 4 bytes of out; outside of src it is black.
+func sampleBilinear(src *image.NRGBA, out []uint8, x, y float64) {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if x < 0 || y < 0 || x > float64(w-1) || y > float64(h-1) {
+		out[0], out[1], out[2], out[3] = 0, 0, 0, 255
+		return
+	}
+
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	x1, y1 := minInt(x0+1, w-1), minInt(y0+1, h-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	p00 := src.Pix[y0*src.Stride+4*x0:]
+	p10 := src.Pix[y0*src.Stride+4*x1:]
+	p01 := src.Pix[y1*src.Stride+4*x0:]
+	p11 := src.Pix[y1*src.Stride+4*x1:]
+	for i := 0; i < 4; i++ {
+		top := float64(p00[i])*(1-fx) + float64(p10[i])*fx
+		bottom := float64(p01[i])*(1-fx) + float64(p11[i])*fx
+		out[i] = uint8(top*(1-fy) + bottom*fy + 0.5)
+	}
+}
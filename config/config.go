@@ -5,10 +5,12 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 	"time"
+	_ "time/tzdata" // time zones of overlays must be available in minimal docker images
 
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v2"
@@ -18,6 +20,11 @@ const NameRegexp = "^[a-zA-Z0-9\\-]{1,32}$"
 
 var nameMatcher = regexp.MustCompile(NameRegexp)
 
+// CameraTypes lists the image sources a camera can be configured with.
+var CameraTypes = []string{"rtsp", "rtspStream", "http", "file", "testPattern"}
+
+var ImageFormats = []string{"jpg", "webp", "avif"}
+
 func ReadConfigFile(exe, source string) (config Config, err []error) {
 	yamlStr, e := os.ReadFile(source)
 	if e != nil {
@@ -69,6 +76,9 @@ func (c configRead) TransformAndValidate() (ret Config, err []error) {
 	ret.httpServer, e = c.HttpServer.TransformAndValidate()
 	err = append(err, e...)
 
+	ret.archive, e = c.Archive.TransformAndValidate(ret.cameras)
+	err = append(err, e...)
+
 	if c.Version == nil {
 		err = append(err, fmt.Errorf("version must be defined. Use Version=0"))
 	} else {
@@ -162,6 +172,7 @@ func (c *httpServerConfigRead) TransformAndValidate() (ret HttpServerConfig, err
 	ret.enabled = false
 	ret.bind = "[::1]"
 	ret.port = 8043
+	ret.readyMinCameras = 1
 
 	if randString, e := randomString(64); err == nil {
 		ret.hashSecret = randString
@@ -187,6 +198,18 @@ func (c *httpServerConfigRead) TransformAndValidate() (ret HttpServerConfig, err
 		ret.logRequests = true
 	}
 
+	if c.Metrics != nil && *c.Metrics {
+		ret.metrics = true
+	}
+
+	if c.ReadyMinCameras != nil {
+		if *c.ReadyMinCameras >= 0 {
+			ret.readyMinCameras = *c.ReadyMinCameras
+		} else {
+			err = append(err, fmt.Errorf("HttpServerConfig->ReadyMinCameras=%d but must be a positive integer or zero", *c.ReadyMinCameras))
+		}
+	}
+
 	if len(c.FrontendProxy) > 0 {
 		u, parseError := url.Parse(c.FrontendProxy)
 		if parseError == nil {
@@ -257,6 +280,122 @@ func (c *httpServerConfigRead) TransformAndValidate() (ret HttpServerConfig, err
 	return
 }
 
+func (c *archiveConfigRead) TransformAndValidate(cameras []*CameraConfig) (ret ArchiveConfig, err []error) {
+	ret.enabled = false
+
+	if c == nil {
+		return
+	}
+
+	ret.enabled = true
+
+	if len(c.Directory) < 1 {
+		err = append(err, fmt.Errorf("Archive->Directory must not be empty"))
+	} else {
+		ret.directory = c.Directory
+	}
+
+	if len(c.Interval) < 1 {
+		// use default 1m
+		ret.interval = time.Minute
+	} else if interval, e := time.ParseDuration(c.Interval); e != nil {
+		err = append(err, fmt.Errorf("Archive->Interval='%s' parse error: %s", c.Interval, e))
+	} else if interval <= 0 {
+		err = append(err, fmt.Errorf("Archive->Interval='%s' must be positive", c.Interval))
+	} else {
+		ret.interval = interval
+	}
+
+	if len(c.MaxAge) > 0 {
+		if maxAge, e := time.ParseDuration(c.MaxAge); e != nil {
+			err = append(err, fmt.Errorf("Archive->MaxAge='%s' parse error: %s", c.MaxAge, e))
+		} else if maxAge < 0 {
+			err = append(err, fmt.Errorf("Archive->MaxAge='%s' must be positive or zero", c.MaxAge))
+		} else {
+			ret.maxAge = maxAge
+		}
+	}
+
+	if c.MaxSizeMb != nil {
+		if *c.MaxSizeMb < 0 {
+			err = append(err, fmt.Errorf("Archive->MaxSizeMb=%d must be positive or zero", *c.MaxSizeMb))
+		} else {
+			ret.maxSize = *c.MaxSizeMb * 1024 * 1024
+		}
+	}
+
+	if c.ResolutionMaxWidth != nil {
+		ret.resolutionMaxWidth = *c.ResolutionMaxWidth
+	}
+	if c.ResolutionMaxHeight != nil {
+		ret.resolutionMaxHeight = *c.ResolutionMaxHeight
+	}
+	if (ret.resolutionMaxWidth != 0 || ret.resolutionMaxHeight != 0) &&
+		(ret.resolutionMaxWidth < 1 || ret.resolutionMaxHeight < 1) {
+		err = append(err, fmt.Errorf(
+			"Archive->ResolutionMaxWidth=%d and Archive->ResolutionMaxHeight=%d must either both be zero or both be positive",
+			ret.resolutionMaxWidth, ret.resolutionMaxHeight,
+		))
+	}
+
+	if c.JpgQuality == nil {
+		ret.jpgQuality = 85
+	} else if *c.JpgQuality > 0 && *c.JpgQuality <= 100 {
+		ret.jpgQuality = *c.JpgQuality
+	} else {
+		err = append(err, fmt.Errorf("Archive->JpgQuality=%d but must be between 1 and 100", *c.JpgQuality))
+	}
+
+	if len(c.Cameras) < 1 {
+		ret.cameras = make([]string, len(cameras))
+		for i, camera := range cameras {
+			ret.cameras[i] = camera.Name()
+		}
+	} else {
+		for _, cameraName := range c.Cameras {
+			if !cameraExists(cameraName, cameras) {
+				err = append(err, fmt.Errorf("Archive->Cameras: camera='%s' is not defined", cameraName))
+			}
+		}
+		ret.cameras = c.Cameras
+	}
+
+	if c.TimelapseFps == nil {
+		ret.timelapseFps = 25
+	} else if *c.TimelapseFps > 0 && *c.TimelapseFps <= 120 {
+		ret.timelapseFps = *c.TimelapseFps
+	} else {
+		err = append(err, fmt.Errorf("Archive->TimelapseFps=%d but must be between 1 and 120", *c.TimelapseFps))
+	}
+
+	if c.TimelapseMaxFrames == nil {
+		ret.timelapseMaxFrames = 3600
+	} else if *c.TimelapseMaxFrames > 1 {
+		ret.timelapseMaxFrames = *c.TimelapseMaxFrames
+	} else {
+		err = append(err, fmt.Errorf("Archive->TimelapseMaxFrames=%d but must be at least 2", *c.TimelapseMaxFrames))
+	}
+
+	if len(c.TimelapseCacheDirectory) < 1 {
+		ret.timelapseCacheDirectory = filepath.Join(ret.directory, ".timelapse")
+	} else {
+		ret.timelapseCacheDirectory = c.TimelapseCacheDirectory
+	}
+
+	if len(c.TimelapseCacheMaxAge) < 1 {
+		// use default 24h
+		ret.timelapseCacheMaxAge = 24 * time.Hour
+	} else if maxAge, e := time.ParseDuration(c.TimelapseCacheMaxAge); e != nil {
+		err = append(err, fmt.Errorf("Archive->TimelapseCacheMaxAge='%s' parse error: %s", c.TimelapseCacheMaxAge, e))
+	} else if maxAge <= 0 {
+		err = append(err, fmt.Errorf("Archive->TimelapseCacheMaxAge='%s' must be positive", c.TimelapseCacheMaxAge))
+	} else {
+		ret.timelapseCacheMaxAge = maxAge
+	}
+
+	return
+}
+
 func (c mqttClientConfigReadMap) getOrderedKeys() (ret []string) {
 	ret = make([]string, len(c))
 	i := 0
@@ -319,6 +458,91 @@ func (c mqttClientConfigRead) TransformAndValidate(name string) (ret MqttClientC
 		ret.logDebug = true
 	}
 
+	if c.CameraStatusTopic == nil {
+		// use default
+		ret.cameraStatusTopic = "%Prefix%webcam/%Camera%/status"
+	} else {
+		ret.cameraStatusTopic = *c.CameraStatusTopic
+	}
+
+	if c.CameraImageTopic != nil {
+		ret.cameraImageTopic = *c.CameraImageTopic
+	}
+
+	if c.CameraCommandTopic == nil {
+		// use default
+		ret.cameraCommandTopic = "%Prefix%webcam/%Camera%/command"
+	} else {
+		ret.cameraCommandTopic = *c.CameraCommandTopic
+	}
+
+	if c.CameraMotionTopic == nil {
+		// use default
+		ret.cameraMotionTopic = "%Prefix%webcam/%Camera%/motion"
+	} else {
+		ret.cameraMotionTopic = *c.CameraMotionTopic
+	}
+
+	if len(c.CameraPublishInterval) < 1 {
+		// use default 10s
+		ret.cameraPublishInterval = 10 * time.Second
+	} else if cameraPublishInterval, e := time.ParseDuration(c.CameraPublishInterval); e != nil {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->CameraPublishInterval='%s' parse error: %s",
+			name, c.CameraPublishInterval, e,
+		))
+	} else if cameraPublishInterval <= 0 {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->CameraPublishInterval='%s' must be positive",
+			name, c.CameraPublishInterval,
+		))
+	} else {
+		ret.cameraPublishInterval = cameraPublishInterval
+	}
+
+	if c.CameraImageMaxWidth == nil {
+		ret.cameraImageMaxWidth = 640
+	} else if *c.CameraImageMaxWidth > 0 {
+		ret.cameraImageMaxWidth = *c.CameraImageMaxWidth
+	} else {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->CameraImageMaxWidth=%d but must be a positive integer", name, *c.CameraImageMaxWidth))
+	}
+
+	if c.CameraImageMaxHeight == nil {
+		ret.cameraImageMaxHeight = 480
+	} else if *c.CameraImageMaxHeight > 0 {
+		ret.cameraImageMaxHeight = *c.CameraImageMaxHeight
+	} else {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->CameraImageMaxHeight=%d but must be a positive integer", name, *c.CameraImageMaxHeight))
+	}
+
+	if c.CameraImageJpgQuality == nil {
+		ret.cameraImageJpgQuality = 85
+	} else if *c.CameraImageJpgQuality > 0 && *c.CameraImageJpgQuality <= 100 {
+		ret.cameraImageJpgQuality = *c.CameraImageJpgQuality
+	} else {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->CameraImageJpgQuality=%d but must be >0 and <= 100", name, *c.CameraImageJpgQuality))
+	}
+
+	if c.HomeAssistantDiscovery != nil && *c.HomeAssistantDiscovery {
+		ret.homeAssistantDiscovery = true
+		if len(ret.cameraStatusTopic) < 1 || len(ret.cameraImageTopic) < 1 {
+			err = append(err, fmt.Errorf("MqttClientConfig->%s->HomeAssistantDiscovery needs CameraStatusTopic and CameraImageTopic to be set", name))
+		}
+	}
+
+	if len(c.HomeAssistantDiscoveryPrefix) < 1 {
+		ret.homeAssistantDiscoveryPrefix = "homeassistant"
+	} else {
+		ret.homeAssistantDiscoveryPrefix = c.HomeAssistantDiscoveryPrefix
+	}
+
+	if len(c.HomeAssistantNodeId) < 1 {
+		ret.homeAssistantNodeId = "go-webcam"
+	} else if nameMatcher.MatchString(c.HomeAssistantNodeId) {
+		ret.homeAssistantNodeId = c.HomeAssistantNodeId
+	} else {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->HomeAssistantNodeId='%s' does not match %s", name, c.HomeAssistantNodeId, NameRegexp))
+	}
+
 	return
 }
 
@@ -351,18 +575,71 @@ func (c cameraConfigReadMap) TransformAndValidate() (ret []*CameraConfig, err []
 
 func (c cameraConfigRead) TransformAndValidate(name string) (ret CameraConfig, err []error) {
 	ret = CameraConfig{
-		name:    name,
-		address: c.Address,
+		name:     name,
+		address:  c.Address,
+		user:     c.User,
+		password: c.Password,
 	}
 
 	if !nameMatcher.MatchString(ret.name) {
 		err = append(err, fmt.Errorf("CameraConfig->Name='%s' does not match %s", ret.name, NameRegexp))
 	}
 
-	if len(ret.address) < 1 {
+	if len(c.Type) < 1 {
+		ret.cameraType = "rtsp"
+	} else if contains(CameraTypes, c.Type) {
+		ret.cameraType = c.Type
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->Type='%s' must be one of %s",
+			name, c.Type, strings.Join(CameraTypes, ", "),
+		))
+	}
+
+	if len(ret.address) < 1 && ret.cameraType != "testPattern" {
 		err = append(err, fmt.Errorf("CameraConfig->%s->Address must not be empty", name))
 	}
 
+	if len(c.Timeout) < 1 {
+		// use default 10s
+		ret.timeout = 10 * time.Second
+	} else if timeout, e := time.ParseDuration(c.Timeout); e != nil {
+		err = append(err, fmt.Errorf("CameraConfig->%s->Timeout='%s' parse error: %s",
+			name, c.Timeout, e,
+		))
+	} else if timeout <= 0 {
+		err = append(err, fmt.Errorf("CameraConfig->%s->Timeout='%s' must be positive",
+			name, c.Timeout,
+		))
+	} else {
+		ret.timeout = timeout
+	}
+
+	if len(c.FileOrder) < 1 {
+		ret.fileOrder = "newest"
+	} else if c.FileOrder == "newest" || c.FileOrder == "cycle" {
+		ret.fileOrder = c.FileOrder
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->FileOrder='%s' must be newest or cycle",
+			name, c.FileOrder,
+		))
+	}
+
+	if c.ResolutionWidth == nil {
+		ret.resolutionWidth = 1280
+	} else if *c.ResolutionWidth > 0 {
+		ret.resolutionWidth = *c.ResolutionWidth
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->ResolutionWidth=%d but must be a positive integer", name, *c.ResolutionWidth))
+	}
+
+	if c.ResolutionHeight == nil {
+		ret.resolutionHeight = 720
+	} else if *c.ResolutionHeight > 0 {
+		ret.resolutionHeight = *c.ResolutionHeight
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->ResolutionHeight=%d but must be a positive integer", name, *c.ResolutionHeight))
+	}
+
 	if len(c.RefreshInterval) < 1 {
 		// use default 200ms
 		ret.refreshInterval = 200 * time.Millisecond
@@ -393,6 +670,152 @@ func (c cameraConfigRead) TransformAndValidate(name string) (ret CameraConfig, e
 		ret.preemptiveFetch = preemptiveFetch
 	}
 
+	if c.RecentFrames == nil {
+		ret.recentFrames = 10
+	} else if *c.RecentFrames >= 0 && *c.RecentFrames <= CameraMaxRecentFrames {
+		ret.recentFrames = *c.RecentFrames
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->RecentFrames=%d but must be between 0 and %d",
+			name, *c.RecentFrames, CameraMaxRecentFrames,
+		))
+	}
+
+	var e []error
+	ret.motionDetection, e = c.MotionDetection.TransformAndValidate(name)
+	err = append(err, e...)
+
+	ret.transform, e = c.Transform.TransformAndValidate(name)
+	err = append(err, e...)
+
+	return
+}
+
+// CameraMaxRecentFrames limits the memory used to keep the recent frames of a camera.
+const CameraMaxRecentFrames = 100
+
+var TransformFlips = []string{"none", "horizontal", "vertical"}
+
+// TransformMaxLensCorrection limits the lens correction such that the mapping stays monotonic.
+const TransformMaxLensCorrection = 0.3
+
+func (c transformConfigRead) TransformAndValidate(cameraName string) (ret TransformConfig, err []error) {
+	ret = TransformConfig{
+		flip: "none",
+	}
+
+	if c.Rotate != nil {
+		switch *c.Rotate {
+		case 0, 90, 180, 270:
+			ret.rotate = *c.Rotate
+		default:
+			err = append(err, fmt.Errorf("CameraConfig->%s->Transform->Rotate=%d but must be 0, 90, 180 or 270", cameraName, *c.Rotate))
+		}
+	}
+
+	if len(c.Flip) > 0 {
+		if contains(TransformFlips, c.Flip) {
+			ret.flip = c.Flip
+		} else {
+			err = append(err, fmt.Errorf("CameraConfig->%s->Transform->Flip='%s' must be one of %s",
+				cameraName, c.Flip, strings.Join(TransformFlips, ", "),
+			))
+		}
+	}
+
+	if c.LensCorrection != nil {
+		if *c.LensCorrection >= -TransformMaxLensCorrection && *c.LensCorrection <= TransformMaxLensCorrection {
+			ret.lensCorrection = *c.LensCorrection
+		} else {
+			err = append(err, fmt.Errorf("CameraConfig->%s->Transform->LensCorrection=%g but must be between %g and %g",
+				cameraName, *c.LensCorrection, -TransformMaxLensCorrection, TransformMaxLensCorrection,
+			))
+		}
+	}
+
+	return
+}
+
+func (c motionDetectionConfigRead) TransformAndValidate(cameraName string) (ret MotionDetectionConfig, err []error) {
+	ret = MotionDetectionConfig{
+		enabled: false,
+	}
+
+	if c.Enabled != nil && *c.Enabled {
+		ret.enabled = true
+	}
+
+	if c.GridWidth == nil {
+		ret.gridWidth = 32
+	} else if *c.GridWidth > 0 {
+		ret.gridWidth = *c.GridWidth
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->MotionDetection->GridWidth=%d but must be a positive integer", cameraName, *c.GridWidth))
+	}
+
+	if c.GridHeight == nil {
+		ret.gridHeight = 18
+	} else if *c.GridHeight > 0 {
+		ret.gridHeight = *c.GridHeight
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->MotionDetection->GridHeight=%d but must be a positive integer", cameraName, *c.GridHeight))
+	}
+
+	if c.Threshold == nil {
+		ret.threshold = 20
+	} else if *c.Threshold > 0 && *c.Threshold < 256 {
+		ret.threshold = *c.Threshold
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->MotionDetection->Threshold=%d but must be between 1 and 255", cameraName, *c.Threshold))
+	}
+
+	if c.MinArea == nil {
+		ret.minArea = 1
+	} else if *c.MinArea > 0 && *c.MinArea <= 100 {
+		ret.minArea = *c.MinArea
+	} else {
+		err = append(err, fmt.Errorf("CameraConfig->%s->MotionDetection->MinArea=%g but must be a percentage greater than 0 and at most 100", cameraName, *c.MinArea))
+	}
+
+	if len(c.Cooldown) < 1 {
+		// use default 10s
+		ret.cooldown = 10 * time.Second
+	} else if cooldown, e := time.ParseDuration(c.Cooldown); e != nil {
+		err = append(err, fmt.Errorf("CameraConfig->%s->MotionDetection->Cooldown='%s' parse error: %s",
+			cameraName, c.Cooldown, e,
+		))
+	} else if cooldown < 0 {
+		err = append(err, fmt.Errorf("CameraConfig->%s->MotionDetection->Cooldown='%s' must be positive or zero",
+			cameraName, c.Cooldown,
+		))
+	} else {
+		ret.cooldown = cooldown
+	}
+
+	var e []error
+	ret.ignoreMasks, e = c.IgnoreMasks.TransformAndValidate(
+		fmt.Sprintf("CameraConfig->%s->MotionDetection->IgnoreMasks", cameraName),
+	)
+	err = append(err, e...)
+
+	return
+}
+
+func (c regionConfigReadList) TransformAndValidate(path string) (ret []RegionConfig, err []error) {
+	ret = make([]RegionConfig, len(c))
+	for i, r := range c {
+		if r.Width <= 0 || r.Height <= 0 {
+			err = append(err, fmt.Errorf("%s[%d]: Width=%g and Height=%g must be positive", path, i, r.Width, r.Height))
+		}
+		if r.Left < 0 || r.Top < 0 || r.Left+r.Width > 100 || r.Top+r.Height > 100 {
+			err = append(err, fmt.Errorf("%s[%d]: must lie within the image; all values are percentages", path, i))
+		}
+		ret[i] = RegionConfig{
+			left:   r.Left,
+			top:    r.Top,
+			width:  r.Width,
+			height: r.Height,
+		}
+	}
 	return
 }
 
@@ -496,6 +919,96 @@ func (c viewConfigRead) TransformAndValidate(cameras []*CameraConfig) (ret ViewC
 		ret.hidden = true
 	}
 
+	var e []error
+	ret.overlay, e = c.Overlay.TransformAndValidate(c.Name)
+	err = append(err, e...)
+
+	for _, format := range c.ImageFormats {
+		if !imageFormatExists(format) {
+			err = append(err, fmt.Errorf("Views->%s->ImageFormats: '%s' must be one of %s",
+				c.Name, format, strings.Join(ImageFormats, ", "),
+			))
+		} else if contains(ret.imageFormats, format) {
+			err = append(err, fmt.Errorf("Views->%s->ImageFormats: '%s' is listed twice", c.Name, format))
+		} else {
+			ret.imageFormats = append(ret.imageFormats, format)
+		}
+	}
+	if !contains(ret.imageFormats, "jpg") {
+		// jpg is understood by every client; it is used when none of the preferred formats is accepted
+		ret.imageFormats = append(ret.imageFormats, "jpg")
+	}
+
+	return
+}
+
+var OverlayPositions = []string{"top-left", "top-right", "bottom-left", "bottom-right"}
+
+func (c *overlayConfigRead) TransformAndValidate(viewName string) (ret OverlayConfig, err []error) {
+	ret = OverlayConfig{
+		enabled:         false,
+		title:           true,
+		timestamp:       true,
+		timestampFormat: "2006-01-02 15:04:05",
+		timeZone:        time.Local,
+		position:        "bottom-left",
+	}
+
+	if c == nil {
+		return
+	}
+
+	ret.enabled = true
+
+	if c.Title != nil {
+		ret.title = *c.Title
+	}
+
+	if c.Timestamp != nil {
+		ret.timestamp = *c.Timestamp
+	}
+
+	if len(c.TimestampFormat) > 0 {
+		ret.timestampFormat = c.TimestampFormat
+	}
+
+	if len(c.TimeZone) > 0 {
+		if loc, e := time.LoadLocation(c.TimeZone); e != nil {
+			err = append(err, fmt.Errorf("Views->%s->Overlay->TimeZone='%s' is invalid: %s", viewName, c.TimeZone, e))
+		} else {
+			ret.timeZone = loc
+		}
+	}
+
+	ret.text = c.Text
+
+	if len(c.Logo) > 0 {
+		if info, e := os.Stat(c.Logo); e != nil {
+			err = append(err, fmt.Errorf("Views->%s->Overlay->Logo='%s' cannot open file. error: %s", viewName, c.Logo, e))
+		} else if info.IsDir() {
+			err = append(err, fmt.Errorf("Views->%s->Overlay->Logo='%s' must be a file, not a directory", viewName, c.Logo))
+		}
+		ret.logo = c.Logo
+	}
+
+	if len(c.Position) > 0 {
+		if contains(OverlayPositions, c.Position) {
+			ret.position = c.Position
+		} else {
+			err = append(err, fmt.Errorf("Views->%s->Overlay->Position='%s' must be one of %s",
+				viewName, c.Position, strings.Join(OverlayPositions, ", "),
+			))
+		}
+	}
+
+	if c.FontSize != nil {
+		if *c.FontSize >= 0 {
+			ret.fontSize = *c.FontSize
+		} else {
+			err = append(err, fmt.Errorf("Views->%s->Overlay->FontSize=%d must be positive or zero", viewName, *c.FontSize))
+		}
+	}
+
 	return
 }
 
@@ -507,6 +1020,14 @@ func (c viewCameraConfigReadList) TransformAndValidate(cameras []*CameraConfig)
 	ret = make([]*ViewCameraConfig, len(c))
 	for i, camera := range c {
 		r, e := camera.TransformAndValidate(cameras)
+
+		// check for duplicate name
+		for j := 0; j < i; j++ {
+			if r.Name() == ret[j].Name() {
+				err = append(err, fmt.Errorf("camera='%s': name must be unique", r.Name()))
+			}
+		}
+
 		ret[i] = &r
 		err = append(err, e...)
 	}
@@ -517,18 +1038,133 @@ func (c viewCameraConfigReadList) TransformAndValidate(cameras []*CameraConfig)
 func (c viewCameraConfigRead) TransformAndValidate(
 	cameras []*CameraConfig,
 ) (ret ViewCameraConfig, err []error) {
-	if !cameraExists(c.Name, cameras) {
-		err = append(err, fmt.Errorf("camera='%s' is not defined", c.Name))
+	ret = ViewCameraConfig{
+		name:   c.Name,
+		title:  c.Title,
+		source: c.Name,
 	}
 
-	ret = ViewCameraConfig{
-		name:  c.Name,
-		title: c.Title,
+	if len(c.Source) > 0 {
+		ret.source = c.Source
+		if !nameMatcher.MatchString(c.Name) {
+			err = append(err, fmt.Errorf("camera='%s': Name does not match %s", c.Name, NameRegexp))
+		}
+	}
+
+	if !cameraExists(ret.source, cameras) {
+		err = append(err, fmt.Errorf("camera='%s' is not defined", ret.source))
+	}
+
+	var e []error
+	ret.crop, e = c.Crop.TransformAndValidate(c.Name)
+	err = append(err, e...)
+
+	ret.privacyMasks, e = c.PrivacyMasks.TransformAndValidate(c.Name)
+	err = append(err, e...)
+
+	return
+}
+
+func (c *cropConfigRead) TransformAndValidate(cameraName string) (ret CropConfig, err []error) {
+	if c == nil {
+		return
+	}
+
+	ret = CropConfig{
+		enabled: true,
+		left:    c.Left,
+		top:     c.Top,
+		width:   c.Width,
+		height:  c.Height,
+	}
+
+	if c.Width <= 0 || c.Height <= 0 {
+		err = append(err, fmt.Errorf("camera='%s'->Crop: Width=%g and Height=%g must be positive", cameraName, c.Width, c.Height))
 	}
+	if c.Left < 0 || c.Top < 0 || c.Left+c.Width > 100 || c.Top+c.Height > 100 {
+		err = append(err, fmt.Errorf("camera='%s'->Crop: must lie within the image; all values are percentages", cameraName))
+	}
+
+	return
+}
+
+var PrivacyMaskFills = []string{"solid", "blur"}
 
+func (c privacyMaskConfigReadList) TransformAndValidate(cameraName string) (ret []PrivacyMaskConfig, err []error) {
+	ret = make([]PrivacyMaskConfig, len(c))
+	for i, m := range c {
+		var e []error
+		ret[i], e = m.TransformAndValidate(fmt.Sprintf("camera='%s'->PrivacyMasks[%d]", cameraName, i))
+		err = append(err, e...)
+	}
 	return
 }
 
+func (c privacyMaskConfigRead) TransformAndValidate(path string) (ret PrivacyMaskConfig, err []error) {
+	ret.fill = "solid"
+	if len(c.Fill) > 0 {
+		if contains(PrivacyMaskFills, c.Fill) {
+			ret.fill = c.Fill
+		} else {
+			err = append(err, fmt.Errorf("%s: Fill='%s' must be one of %s",
+				path, c.Fill, strings.Join(PrivacyMaskFills, ", "),
+			))
+		}
+	}
+
+	isRectangle := c.Left != nil || c.Top != nil || c.Width != nil || c.Height != nil
+	if isRectangle == (len(c.Polygon) > 0) {
+		err = append(err, fmt.Errorf("%s: either Left, Top, Width and Height or Polygon must be set", path))
+		return
+	}
+
+	if isRectangle {
+		if c.Left == nil || c.Top == nil || c.Width == nil || c.Height == nil {
+			err = append(err, fmt.Errorf("%s: Left, Top, Width and Height must all be set", path))
+			return
+		}
+		if *c.Width <= 0 || *c.Height <= 0 {
+			err = append(err, fmt.Errorf("%s: Width=%g and Height=%g must be positive", path, *c.Width, *c.Height))
+		}
+		left, top, right, bottom := *c.Left, *c.Top, *c.Left+*c.Width, *c.Top+*c.Height
+		ret.polygon = [][2]float64{{left, top}, {right, top}, {right, bottom}, {left, bottom}}
+	} else {
+		if len(c.Polygon) < 3 {
+			err = append(err, fmt.Errorf("%s: Polygon must have at least 3 points", path))
+		}
+		ret.polygon = make([][2]float64, len(c.Polygon))
+		for i, p := range c.Polygon {
+			if len(p) != 2 {
+				err = append(err, fmt.Errorf("%s: Polygon[%d] must be a pair of x and y", path, i))
+				continue
+			}
+			ret.polygon[i] = [2]float64{p[0], p[1]}
+		}
+	}
+
+	for _, p := range ret.polygon {
+		if p[0] < 0 || p[0] > 100 || p[1] < 0 || p[1] > 100 {
+			err = append(err, fmt.Errorf("%s: coordinates must be percentages between 0 and 100", path))
+			break
+		}
+	}
+
+	return
+}
+
+func imageFormatExists(format string) bool {
+	return contains(ImageFormats, format)
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
 func cameraExists(cameraName string,
 	cameras []*CameraConfig) bool {
 	for _, client := range cameras {
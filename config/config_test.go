@@ -0,0 +1,273 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConfig returns a configuration with a single camera cam-a shown in the view pub.
+// The given options are added to the camera, the view camera and the view; sections are added at the top level.
+func testConfig(camera, viewCamera, view, sections string) []byte {
+	return []byte("Version: 0\n" +
+		"Cameras:\n" +
+		"  cam-a:\n" + indent(camera, "    ") +
+		"Views:\n" +
+		"  - Name: pub\n" +
+		"    Title: Public\n" + indent(view, "    ") +
+		"    Cameras:\n" +
+		"      - Name: cam-a\n" +
+		"        Title: A\n" + indent(viewCamera, "        ") +
+		sections,
+	)
+}
+
+func indent(s, prefix string) (ret string) {
+	for _, line := range strings.Split(s, "\n") {
+		if len(line) > 0 {
+			ret += prefix + line + "\n"
+		}
+	}
+	return
+}
+
+func TestReadConfigDefaults(t *testing.T) {
+	cfg, err := ReadConfig(testConfig("Type: testPattern", "", "", ""))
+	if len(err) > 0 {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+
+	cameras := cfg.Cameras()
+	if len(cameras) != 1 {
+		t.Fatalf("expected 1 camera, got %d", len(cameras))
+	}
+	camera := cameras[0]
+	if got := camera.Type(); got != "testPattern" {
+		t.Errorf("Type: expected testPattern, got %s", got)
+	}
+	if got := camera.FileOrder(); got != "newest" {
+		t.Errorf("FileOrder: expected newest, got %s", got)
+	}
+	if got := camera.Timeout(); got != 10*time.Second {
+		t.Errorf("Timeout: expected 10s, got %s", got)
+	}
+	if got := camera.RecentFrames(); got != 10 {
+		t.Errorf("RecentFrames: expected 10, got %d", got)
+	}
+	if got := camera.Transform().Flip(); got != "none" {
+		t.Errorf("Transform->Flip: expected none, got %s", got)
+	}
+	if camera.MotionDetection().Enabled() {
+		t.Errorf("MotionDetection: expected disabled")
+	}
+
+	if cfg.Archive().Enabled() {
+		t.Errorf("Archive: expected disabled")
+	}
+
+	view := cfg.Views()[0]
+	if got := view.ImageFormats(); len(got) != 1 || got[0] != "jpg" {
+		t.Errorf("ImageFormats: expected [jpg], got %v", got)
+	}
+	if view.Cameras()[0].Crop().Enabled() {
+		t.Errorf("Crop: expected disabled")
+	}
+}
+
+func TestReadConfigValues(t *testing.T) {
+	cfg, err := ReadConfig(testConfig(
+		"Type: file\n"+
+			"Address: /tmp\n"+
+			"FileOrder: cycle\n"+
+			"RecentFrames: 0\n"+
+			"MotionDetection:\n"+
+			"  Enabled: True\n"+
+			"  IgnoreMasks:\n"+
+			"    - {Left: 0, Top: 0, Width: 30, Height: 5}\n"+
+			"Transform:\n"+
+			"  Rotate: 90\n"+
+			"  Flip: horizontal\n",
+		"Crop: {Left: 50, Top: 0, Width: 50, Height: 100}\n"+
+			"PrivacyMasks:\n"+
+			"  - {Left: 10, Top: 20, Width: 30, Height: 40, Fill: blur}\n",
+		"ImageFormats: [webp]\n"+
+			"Overlay:\n"+
+			"  Position: top-right\n",
+		"Archive:\n"+
+			"  Directory: /tmp/archive\n"+
+			"  Interval: 30s\n",
+	))
+	if len(err) > 0 {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+
+	camera := cfg.Cameras()[0]
+	if got := camera.FileOrder(); got != "cycle" {
+		t.Errorf("FileOrder: expected cycle, got %s", got)
+	}
+	if got := camera.RecentFrames(); got != 0 {
+		t.Errorf("RecentFrames: expected 0, got %d", got)
+	}
+	masks := camera.MotionDetection().IgnoreMasks()
+	if len(masks) != 1 || masks[0].Width() != 30 || masks[0].Height() != 5 {
+		t.Errorf("IgnoreMasks: unexpected %v", masks)
+	}
+	if got := camera.Transform(); got.Rotate() != 90 || got.Flip() != "horizontal" {
+		t.Errorf("Transform: expected 90 and horizontal, got %d and %s", got.Rotate(), got.Flip())
+	}
+
+	view := cfg.Views()[0]
+	if got := view.ImageFormats(); len(got) != 2 || got[0] != "webp" || got[1] != "jpg" {
+		t.Errorf("ImageFormats: expected [webp jpg], got %v", got)
+	}
+	if got := view.Overlay().Position(); got != "top-right" {
+		t.Errorf("Overlay->Position: expected top-right, got %s", got)
+	}
+
+	viewCamera := view.Cameras()[0]
+	if crop := viewCamera.Crop(); !crop.Enabled() || crop.Left() != 50 || crop.Width() != 50 {
+		t.Errorf("Crop: unexpected %+v", crop)
+	}
+	privacyMasks := viewCamera.PrivacyMasks()
+	if len(privacyMasks) != 1 || privacyMasks[0].Fill() != "blur" || len(privacyMasks[0].Polygon()) != 4 {
+		t.Fatalf("PrivacyMasks: unexpected %v", privacyMasks)
+	}
+	if got := privacyMasks[0].Polygon()[2]; got != [2]float64{40, 60} {
+		t.Errorf("PrivacyMasks: expected the bottom right corner at 40,60, got %v", got)
+	}
+
+	archive := cfg.Archive()
+	if !archive.Enabled() || archive.Directory() != "/tmp/archive" || archive.Interval() != 30*time.Second {
+		t.Errorf("Archive: unexpected %+v", archive)
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		camera     string
+		viewCamera string
+		view       string
+		sections   string
+		expected   string
+	}{
+		{
+			name:     "unknown type",
+			camera:   "Type: ftp",
+			expected: "CameraConfig->cam-a->Type='ftp' must be one of",
+		},
+		{
+			name:     "missing address",
+			camera:   "Type: http",
+			expected: "CameraConfig->cam-a->Address must not be empty",
+		},
+		{
+			name:     "invalid file order",
+			camera:   "Type: file\nAddress: /tmp\nFileOrder: random",
+			expected: "CameraConfig->cam-a->FileOrder='random' must be newest or cycle",
+		},
+		{
+			name:     "too many recent frames",
+			camera:   "Type: testPattern\nRecentFrames: 101",
+			expected: "CameraConfig->cam-a->RecentFrames=101",
+		},
+		{
+			name:     "invalid rotation",
+			camera:   "Type: testPattern\nTransform:\n  Rotate: 45",
+			expected: "CameraConfig->cam-a->Transform->Rotate=45",
+		},
+		{
+			name:     "lens correction out of range",
+			camera:   "Type: testPattern\nTransform:\n  LensCorrection: 0.5",
+			expected: "CameraConfig->cam-a->Transform->LensCorrection=0.5",
+		},
+		{
+			name:     "negative motion cooldown",
+			camera:   "Type: testPattern\nMotionDetection:\n  Cooldown: -1s",
+			expected: "CameraConfig->cam-a->MotionDetection->Cooldown='-1s' must be positive or zero",
+		},
+		{
+			name:     "ignore mask outside of the image",
+			camera:   "Type: testPattern\nMotionDetection:\n  IgnoreMasks:\n    - {Left: 90, Top: 0, Width: 20, Height: 5}",
+			expected: "CameraConfig->cam-a->MotionDetection->IgnoreMasks[0]: must lie within the image",
+		},
+		{
+			name:       "crop outside of the image",
+			camera:     "Type: testPattern",
+			viewCamera: "Crop: {Left: 50, Top: 0, Width: 60, Height: 10}",
+			expected:   "camera='cam-a'->Crop: must lie within the image",
+		},
+		{
+			name:       "unknown privacy mask fill",
+			camera:     "Type: testPattern",
+			viewCamera: "PrivacyMasks:\n  - {Left: 0, Top: 0, Width: 10, Height: 10, Fill: pixelate}",
+			expected:   "camera='cam-a'->PrivacyMasks[0]: Fill='pixelate' must be one of",
+		},
+		{
+			name:       "privacy mask polygon with two points",
+			camera:     "Type: testPattern",
+			viewCamera: "PrivacyMasks:\n  - Polygon: [[0, 0], [10, 10]]",
+			expected:   "camera='cam-a'->PrivacyMasks[0]: Polygon must have at least 3 points",
+		},
+		{
+			name:       "privacy mask with rectangle and polygon",
+			camera:     "Type: testPattern",
+			viewCamera: "PrivacyMasks:\n  - {Left: 0, Top: 0, Width: 10, Height: 10, Polygon: [[0, 0], [10, 0], [0, 10]]}",
+			expected:   "either Left, Top, Width and Height or Polygon must be set",
+		},
+		{
+			name:     "unknown overlay position",
+			camera:   "Type: testPattern",
+			view:     "Overlay:\n  Position: center",
+			expected: "Views->pub->Overlay->Position='center' must be one of",
+		},
+		{
+			name:     "unknown image format",
+			camera:   "Type: testPattern",
+			view:     "ImageFormats: [webp, gif]",
+			expected: "Views->pub->ImageFormats: 'gif' must be one of",
+		},
+		{
+			name:     "duplicate image format",
+			camera:   "Type: testPattern",
+			view:     "ImageFormats: [webp, webp]",
+			expected: "Views->pub->ImageFormats: 'webp' is listed twice",
+		},
+		{
+			name:     "archive without directory",
+			camera:   "Type: testPattern",
+			sections: "Archive:\n  Interval: 1m\n",
+			expected: "Archive->Directory must not be empty",
+		},
+		{
+			name:     "negative archive interval",
+			camera:   "Type: testPattern",
+			sections: "Archive:\n  Directory: /tmp/archive\n  Interval: -1s\n",
+			expected: "Archive->Interval='-1s' must be positive",
+		},
+		{
+			name:     "archive of an unknown camera",
+			camera:   "Type: testPattern",
+			sections: "Archive:\n  Directory: /tmp/archive\n  Cameras: [cam-x]\n",
+			expected: "Archive->Cameras: camera='cam-x' is not defined",
+		},
+		{
+			name:     "negative ready min cameras",
+			camera:   "Type: testPattern",
+			sections: "HttpServer:\n  ReadyMinCameras: -1\n",
+			expected: "HttpServerConfig->ReadyMinCameras=-1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadConfig(testConfig(tc.camera, tc.viewCamera, tc.view, tc.sections))
+			for _, e := range err {
+				if strings.Contains(e.Error(), tc.expected) {
+					return
+				}
+			}
+			t.Errorf("expected an error containing '%s', got %v", tc.expected, err)
+		})
+	}
+}
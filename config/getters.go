@@ -33,6 +33,10 @@ func (c Config) HttpServer() HttpServerConfig {
 	return c.httpServer
 }
 
+func (c Config) Archive() ArchiveConfig {
+	return c.archive
+}
+
 func (c Config) LogConfig() bool {
 	return c.logConfig
 }
@@ -101,14 +105,86 @@ func (c MqttClientConfig) LogDebug() bool {
 	return c.logDebug
 }
 
+func (c MqttClientConfig) CameraStatusTopic() string {
+	return c.cameraStatusTopic
+}
+
+func (c MqttClientConfig) CameraImageTopic() string {
+	return c.cameraImageTopic
+}
+
+func (c MqttClientConfig) CameraCommandTopic() string {
+	return c.cameraCommandTopic
+}
+
+func (c MqttClientConfig) CameraMotionTopic() string {
+	return c.cameraMotionTopic
+}
+
+func (c MqttClientConfig) CameraPublishInterval() time.Duration {
+	return c.cameraPublishInterval
+}
+
+func (c MqttClientConfig) CameraImageMaxWidth() int {
+	return c.cameraImageMaxWidth
+}
+
+func (c MqttClientConfig) CameraImageMaxHeight() int {
+	return c.cameraImageMaxHeight
+}
+
+func (c MqttClientConfig) CameraImageJpgQuality() int {
+	return c.cameraImageJpgQuality
+}
+
+func (c MqttClientConfig) HomeAssistantDiscovery() bool {
+	return c.homeAssistantDiscovery
+}
+
+func (c MqttClientConfig) HomeAssistantDiscoveryPrefix() string {
+	return c.homeAssistantDiscoveryPrefix
+}
+
+func (c MqttClientConfig) HomeAssistantNodeId() string {
+	return c.homeAssistantNodeId
+}
+
 func (c CameraConfig) Name() string {
 	return c.name
 }
 
+func (c CameraConfig) Type() string {
+	return c.cameraType
+}
+
 func (c CameraConfig) Address() string {
 	return c.address
 }
 
+func (c CameraConfig) User() string {
+	return c.user
+}
+
+func (c CameraConfig) Password() string {
+	return c.password
+}
+
+func (c CameraConfig) Timeout() time.Duration {
+	return c.timeout
+}
+
+func (c CameraConfig) FileOrder() string {
+	return c.fileOrder
+}
+
+func (c CameraConfig) ResolutionWidth() int {
+	return c.resolutionWidth
+}
+
+func (c CameraConfig) ResolutionHeight() int {
+	return c.resolutionHeight
+}
+
 func (c CameraConfig) RefreshInterval() time.Duration {
 	return c.refreshInterval
 }
@@ -117,10 +193,78 @@ func (c CameraConfig) PreemptiveFetch() time.Duration {
 	return c.preemptiveFetch
 }
 
+func (c CameraConfig) RecentFrames() int {
+	return c.recentFrames
+}
+
 func (c CameraConfig) ExpireEarly() time.Duration {
 	return 0
 }
 
+func (c CameraConfig) MotionDetection() MotionDetectionConfig {
+	return c.motionDetection
+}
+
+func (c CameraConfig) Transform() TransformConfig {
+	return c.transform
+}
+
+func (c TransformConfig) Rotate() int {
+	return c.rotate
+}
+
+func (c TransformConfig) Flip() string {
+	return c.flip
+}
+
+func (c TransformConfig) LensCorrection() float64 {
+	return c.lensCorrection
+}
+
+func (c MotionDetectionConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c MotionDetectionConfig) GridWidth() int {
+	return c.gridWidth
+}
+
+func (c MotionDetectionConfig) GridHeight() int {
+	return c.gridHeight
+}
+
+func (c MotionDetectionConfig) Threshold() int {
+	return c.threshold
+}
+
+func (c MotionDetectionConfig) MinArea() float64 {
+	return c.minArea
+}
+
+func (c MotionDetectionConfig) Cooldown() time.Duration {
+	return c.cooldown
+}
+
+func (c MotionDetectionConfig) IgnoreMasks() []RegionConfig {
+	return c.ignoreMasks
+}
+
+func (c RegionConfig) Left() float64 {
+	return c.left
+}
+
+func (c RegionConfig) Top() float64 {
+	return c.top
+}
+
+func (c RegionConfig) Width() float64 {
+	return c.width
+}
+
+func (c RegionConfig) Height() float64 {
+	return c.height
+}
+
 func (c ViewCameraConfig) Name() string {
 	return c.name
 }
@@ -129,6 +273,46 @@ func (c ViewCameraConfig) Title() string {
 	return c.title
 }
 
+func (c ViewCameraConfig) Source() string {
+	return c.source
+}
+
+func (c ViewCameraConfig) Crop() CropConfig {
+	return c.crop
+}
+
+func (c CropConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c CropConfig) Left() float64 {
+	return c.left
+}
+
+func (c CropConfig) Top() float64 {
+	return c.top
+}
+
+func (c CropConfig) Width() float64 {
+	return c.width
+}
+
+func (c CropConfig) Height() float64 {
+	return c.height
+}
+
+func (c ViewCameraConfig) PrivacyMasks() []PrivacyMaskConfig {
+	return c.privacyMasks
+}
+
+func (c PrivacyMaskConfig) Polygon() [][2]float64 {
+	return c.polygon
+}
+
+func (c PrivacyMaskConfig) Fill() string {
+	return c.fill
+}
+
 func (c ViewConfig) Name() string {
 	return c.name
 }
@@ -178,10 +362,18 @@ func (c ViewConfig) IsPublic() bool {
 	return len(c.allowedUsers) == 0
 }
 
+func (c ViewConfig) Overlay() OverlayConfig {
+	return c.overlay
+}
+
 func (c ViewConfig) Hidden() bool {
 	return c.hidden
 }
 
+func (c ViewConfig) ImageFormats() []string {
+	return c.imageFormats
+}
+
 func (c HttpServerConfig) Enabled() bool {
 	return c.enabled
 }
@@ -198,6 +390,14 @@ func (c HttpServerConfig) LogRequests() bool {
 	return c.logRequests
 }
 
+func (c HttpServerConfig) Metrics() bool {
+	return c.metrics
+}
+
+func (c HttpServerConfig) ReadyMinCameras() int {
+	return c.readyMinCameras
+}
+
 func (c HttpServerConfig) FrontendProxy() *url.URL {
 	return c.frontendProxy
 }
@@ -233,3 +433,99 @@ func (c Config) GetViewNames() (ret []string) {
 	}
 	return
 }
+
+func (c Config) GetMqttClientNames() (ret []string) {
+	ret = []string{}
+	for _, v := range c.MqttClients() {
+		ret = append(ret, v.Name())
+	}
+	return
+}
+
+func (c ArchiveConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c ArchiveConfig) Directory() string {
+	return c.directory
+}
+
+func (c ArchiveConfig) Interval() time.Duration {
+	return c.interval
+}
+
+func (c ArchiveConfig) MaxAge() time.Duration {
+	return c.maxAge
+}
+
+func (c ArchiveConfig) MaxSize() int64 {
+	return c.maxSize
+}
+
+func (c ArchiveConfig) ResolutionMaxWidth() int {
+	return c.resolutionMaxWidth
+}
+
+func (c ArchiveConfig) ResolutionMaxHeight() int {
+	return c.resolutionMaxHeight
+}
+
+func (c ArchiveConfig) JpgQuality() int {
+	return c.jpgQuality
+}
+
+func (c ArchiveConfig) Cameras() []string {
+	return c.cameras
+}
+
+func (c ArchiveConfig) TimelapseFps() int {
+	return c.timelapseFps
+}
+
+func (c ArchiveConfig) TimelapseMaxFrames() int {
+	return c.timelapseMaxFrames
+}
+
+func (c ArchiveConfig) TimelapseCacheDirectory() string {
+	return c.timelapseCacheDirectory
+}
+
+func (c ArchiveConfig) TimelapseCacheMaxAge() time.Duration {
+	return c.timelapseCacheMaxAge
+}
+
+func (c OverlayConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c OverlayConfig) Title() bool {
+	return c.title
+}
+
+func (c OverlayConfig) Timestamp() bool {
+	return c.timestamp
+}
+
+func (c OverlayConfig) TimestampFormat() string {
+	return c.timestampFormat
+}
+
+func (c OverlayConfig) TimeZone() *time.Location {
+	return c.timeZone
+}
+
+func (c OverlayConfig) Text() string {
+	return c.text
+}
+
+func (c OverlayConfig) Logo() string {
+	return c.logo
+}
+
+func (c OverlayConfig) Position() string {
+	return c.position
+}
+
+func (c OverlayConfig) FontSize() int {
+	return c.fontSize
+}
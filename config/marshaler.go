@@ -41,6 +41,13 @@ func (c Config) MarshalYAML() (interface{}, error) {
 			r := c.httpServer.convertToRead()
 			return &r
 		}(),
+		Archive: func() *archiveConfigRead {
+			if !c.archive.enabled {
+				return nil
+			}
+			r := c.archive.convertToRead()
+			return &r
+		}(),
 		LogConfig:      &c.logConfig,
 		LogWorkerStart: &c.logWorkerStart,
 		LogDebug:       &c.logDebug,
@@ -61,27 +68,113 @@ func (c MqttClientConfig) convertToRead() mqttClientConfigRead {
 	return mqttClientConfigRead{
 		Broker:            c.broker,
 		User:              c.user,
-		Password:          c.password,
+		Password:          redactPassword(c.password),
 		ClientId:          c.clientId,
 		Qos:               &c.qos,
 		AvailabilityTopic: &c.availabilityTopic,
 		TopicPrefix:       c.topicPrefix,
 		LogDebug:          &c.logDebug,
+
+		CameraStatusTopic:     &c.cameraStatusTopic,
+		CameraImageTopic:      &c.cameraImageTopic,
+		CameraCommandTopic:    &c.cameraCommandTopic,
+		CameraMotionTopic:     &c.cameraMotionTopic,
+		CameraPublishInterval: c.cameraPublishInterval.String(),
+		CameraImageMaxWidth:   &c.cameraImageMaxWidth,
+		CameraImageMaxHeight:  &c.cameraImageMaxHeight,
+		CameraImageJpgQuality: &c.cameraImageJpgQuality,
+
+		HomeAssistantDiscovery:       &c.homeAssistantDiscovery,
+		HomeAssistantDiscoveryPrefix: c.homeAssistantDiscoveryPrefix,
+		HomeAssistantNodeId:          c.homeAssistantNodeId,
 	}
 }
 
 func (c CameraConfig) convertToRead() cameraConfigRead {
 	return cameraConfigRead{
-		Address:         c.address,
-		RefreshInterval: c.refreshInterval.String(),
-		PreemptiveFetch: c.preemptiveFetch.String(),
+		Type:             c.cameraType,
+		Address:          c.address,
+		User:             c.user,
+		Password:         redactPassword(c.password),
+		Timeout:          c.timeout.String(),
+		FileOrder:        c.fileOrder,
+		ResolutionWidth:  &c.resolutionWidth,
+		ResolutionHeight: &c.resolutionHeight,
+		RefreshInterval:  c.refreshInterval.String(),
+		PreemptiveFetch:  c.preemptiveFetch.String(),
+		RecentFrames:     &c.recentFrames,
+		MotionDetection:  c.motionDetection.convertToRead(),
+		Transform:        c.transform.convertToRead(),
+	}
+}
+
+func (c MotionDetectionConfig) convertToRead() motionDetectionConfigRead {
+	return motionDetectionConfigRead{
+		Enabled:     &c.enabled,
+		GridWidth:   &c.gridWidth,
+		GridHeight:  &c.gridHeight,
+		Threshold:   &c.threshold,
+		MinArea:     &c.minArea,
+		Cooldown:    c.cooldown.String(),
+		IgnoreMasks: convertRegionsToRead(c.ignoreMasks),
+	}
+}
+
+func (c TransformConfig) convertToRead() transformConfigRead {
+	return transformConfigRead{
+		Rotate:         &c.rotate,
+		Flip:           c.flip,
+		LensCorrection: &c.lensCorrection,
+	}
+}
+
+func convertRegionsToRead(regions []RegionConfig) regionConfigReadList {
+	ret := make(regionConfigReadList, len(regions))
+	for i, r := range regions {
+		ret[i] = regionConfigRead{
+			Left:   r.left,
+			Top:    r.top,
+			Width:  r.width,
+			Height: r.height,
+		}
 	}
+	return ret
 }
 
 func (c ViewCameraConfig) convertToRead() viewCameraConfigRead {
 	return viewCameraConfigRead{
-		Name:  c.name,
-		Title: c.title,
+		Name:   c.name,
+		Title:  c.title,
+		Source: c.source,
+		Crop: func() *cropConfigRead {
+			if !c.crop.enabled {
+				return nil
+			}
+			return &cropConfigRead{
+				Left:   c.crop.left,
+				Top:    c.crop.top,
+				Width:  c.crop.width,
+				Height: c.crop.height,
+			}
+		}(),
+		PrivacyMasks: func() privacyMaskConfigReadList {
+			masks := make(privacyMaskConfigReadList, len(c.privacyMasks))
+			for i, m := range c.privacyMasks {
+				masks[i] = m.convertToRead()
+			}
+			return masks
+		}(),
+	}
+}
+
+func (c PrivacyMaskConfig) convertToRead() privacyMaskConfigRead {
+	polygon := make([][]float64, len(c.polygon))
+	for i, p := range c.polygon {
+		polygon[i] = []float64{p[0], p[1]}
+	}
+	return privacyMaskConfigRead{
+		Polygon: polygon,
+		Fill:    c.fill,
 	}
 }
 
@@ -103,6 +196,27 @@ func (c ViewConfig) convertToRead() viewConfigRead {
 		Autoplay:            &c.autoplay,
 		AllowedUsers:        mapKeys(c.allowedUsers),
 		Hidden:              &c.hidden,
+		ImageFormats:        c.imageFormats,
+		Overlay: func() *overlayConfigRead {
+			if !c.overlay.enabled {
+				return nil
+			}
+			r := c.overlay.convertToRead()
+			return &r
+		}(),
+	}
+}
+
+func (c OverlayConfig) convertToRead() overlayConfigRead {
+	return overlayConfigRead{
+		Title:           &c.title,
+		Timestamp:       &c.timestamp,
+		TimestampFormat: c.timestampFormat,
+		TimeZone:        c.timeZone.String(),
+		Text:            c.text,
+		Logo:            c.logo,
+		Position:        c.position,
+		FontSize:        &c.fontSize,
 	}
 }
 
@@ -126,6 +240,8 @@ func (c HttpServerConfig) convertToRead() httpServerConfigRead {
 		Bind:            c.bind,
 		Port:            &c.port,
 		LogRequests:     &c.logRequests,
+		Metrics:         &c.metrics,
+		ReadyMinCameras: &c.readyMinCameras,
 		FrontendProxy:   frontendProxy,
 		FrontendPath:    c.frontendPath,
 		FrontendExpires: c.frontendExpires.String(),
@@ -134,3 +250,30 @@ func (c HttpServerConfig) convertToRead() httpServerConfigRead {
 		HashSecret:      &c.hashSecret,
 	}
 }
+
+func (c ArchiveConfig) convertToRead() archiveConfigRead {
+	maxSizeMb := c.maxSize / 1024 / 1024
+	return archiveConfigRead{
+		Directory:           c.directory,
+		Interval:            c.interval.String(),
+		MaxAge:              c.maxAge.String(),
+		MaxSizeMb:           &maxSizeMb,
+		ResolutionMaxWidth:  &c.resolutionMaxWidth,
+		ResolutionMaxHeight: &c.resolutionMaxHeight,
+		JpgQuality:          &c.jpgQuality,
+		Cameras:             c.cameras,
+
+		TimelapseFps:            &c.timelapseFps,
+		TimelapseMaxFrames:      &c.timelapseMaxFrames,
+		TimelapseCacheDirectory: c.timelapseCacheDirectory,
+		TimelapseCacheMaxAge:    c.timelapseCacheMaxAge.String(),
+	}
+}
+
+// redactPassword hides a password in the printed configuration; an empty one stays empty.
+func redactPassword(password string) string {
+	if len(password) < 1 {
+		return ""
+	}
+	return "********"
+}
@@ -13,6 +13,7 @@ type Config struct {
 	cameras        []*CameraConfig     `yaml:"Cameras"`        // mandatory: at least 1 must be defined
 	views          []*ViewConfig       `yaml:"Views"`          // mandatory: at least 1 must be defined
 	httpServer     HttpServerConfig    `yaml:"HttpServer"`     // optional: default Disabled
+	archive        ArchiveConfig       `yaml:"Archive"`        // optional: default Disabled
 	logConfig      bool                `yaml:"LogConfig"`      // optional: default False
 	logWorkerStart bool                `yaml:"LogWorkerStart"` // optional: default False
 	logDebug       bool                `yaml:"LogDebug"`       // optional: default False
@@ -26,6 +27,23 @@ type AuthConfig struct {
 	logAuth           bool          `yaml:"LogAuth"`           // optional: default False
 }
 
+type ArchiveConfig struct {
+	enabled             bool          // defined automatically if Archive section exists
+	directory           string        // mandatory: root directory of the archive
+	interval            time.Duration // optional: default 1m; how often an image is stored per camera
+	maxAge              time.Duration // optional: default 0 (unlimited); older images are deleted
+	maxSize             int64         // optional: default 0 (unlimited); oldest images are deleted when exceeded
+	resolutionMaxWidth  int           // optional: default 0 (original resolution)
+	resolutionMaxHeight int           // optional: default 0 (original resolution)
+	jpgQuality          int           // optional: default 85; only used when images are resized
+	cameras             []string      // optional: default all cameras
+
+	timelapseFps            int           // optional: default 25; frame rate of rendered time-lapse videos
+	timelapseMaxFrames      int           // optional: default 3600; longer time ranges skip images evenly
+	timelapseCacheDirectory string        // optional: default <directory>/.timelapse
+	timelapseCacheMaxAge    time.Duration // optional: default 24h; unused rendered videos are deleted afterwards
+}
+
 type MqttClientConfig struct {
 	name              string // defined automatically by map key
 	broker            string // mandatory
@@ -36,18 +54,80 @@ type MqttClientConfig struct {
 	availabilityTopic string // optional: default %Prefix%tele/%ClientId%/status
 	topicPrefix       string // optional: default empty
 	logDebug          bool   // optional: default False
+
+	cameraStatusTopic     string        // optional: default %Prefix%webcam/%Camera%/status
+	cameraImageTopic      string        // optional: default empty (disabled)
+	cameraCommandTopic    string        // optional: default %Prefix%webcam/%Camera%/command
+	cameraMotionTopic     string        // optional: default %Prefix%webcam/%Camera%/motion
+	cameraPublishInterval time.Duration // optional: default 10s
+	cameraImageMaxWidth   int           // optional: default 640
+	cameraImageMaxHeight  int           // optional: default 480
+	cameraImageJpgQuality int           // optional: default 85
+
+	homeAssistantDiscovery       bool   // optional: default False
+	homeAssistantDiscoveryPrefix string // optional: default homeassistant
+	homeAssistantNodeId          string // optional: default go-webcam
 }
 
 type CameraConfig struct {
-	name            string        // defined automatically by map key
-	address         string        // mandatory
-	refreshInterval time.Duration // optional: default 200ms
-	preemptiveFetch time.Duration // optional: default 2 x refreshInterval
+	name             string        // defined automatically by map key
+	cameraType       string        // optional: default rtsp
+	address          string        // mandatory unless type is testPattern
+	user             string        // optional: default empty
+	password         string        // optional: default empty
+	timeout          time.Duration // optional: default 10s
+	fileOrder        string        // optional: default newest; how a file is selected when type is file and address a directory
+	resolutionWidth  int           // optional: default 1280; width of the generated images when type is testPattern
+	resolutionHeight int           // optional: default 720; height of the generated images when type is testPattern
+	refreshInterval  time.Duration // optional: default 200ms
+	preemptiveFetch  time.Duration // optional: default 2 x refreshInterval
+	recentFrames     int           // optional: default 10; number of recent frames kept for animated previews
+	motionDetection  MotionDetectionConfig
+	transform        TransformConfig
+}
+
+type TransformConfig struct {
+	rotate         int     // optional: default 0; clockwise in degrees, 0, 90, 180 or 270
+	flip           string  // optional: default none; none, horizontal or vertical
+	lensCorrection float64 // optional: default 0; positive values correct barrel, negative pincushion distortion
+}
+
+type MotionDetectionConfig struct {
+	enabled     bool           // optional: default False
+	gridWidth   int            // optional: default 32; number of cells the image is divided into horizontally
+	gridHeight  int            // optional: default 18; number of cells the image is divided into vertically
+	threshold   int            // optional: default 20; minimum change of the average brightness (0-255) of a cell
+	minArea     float64        // optional: default 1; minimum percentage of changed cells to detect motion
+	cooldown    time.Duration  // optional: default 10s; motion ends after no change was detected for this duration
+	ignoreMasks []RegionConfig // optional: default empty; areas of the transformed image which are ignored
+}
+
+type RegionConfig struct {
+	left   float64 // mandatory: in percent of the image width
+	top    float64 // mandatory: in percent of the image height
+	width  float64 // mandatory: in percent of the image width
+	height float64 // mandatory: in percent of the image height
 }
 
 type ViewCameraConfig struct {
-	name  string // defined automatically by map key
-	title string // mandatory: a nice title for the frontend
+	name         string              // defined automatically by map key
+	title        string              // mandatory: a nice title for the frontend
+	source       string              // optional: default name; the camera the images are taken from
+	crop         CropConfig          // optional: default Disabled
+	privacyMasks []PrivacyMaskConfig // optional: default empty
+}
+
+type CropConfig struct {
+	enabled bool    // defined automatically if Crop section exists
+	left    float64 // mandatory: in percent of the image width
+	top     float64 // mandatory: in percent of the image height
+	width   float64 // mandatory: in percent of the image width
+	height  float64 // mandatory: in percent of the image height
+}
+
+type PrivacyMaskConfig struct {
+	polygon [][2]float64 // mandatory: corners in percent of the image width and height; rectangles are converted
+	fill    string       // optional: default solid; solid or blur
 }
 
 type ViewConfig struct {
@@ -61,6 +141,20 @@ type ViewConfig struct {
 	autoplay            bool                // optional: default false
 	allowedUsers        map[string]struct{} // optional: if empty: view is public; otherwise only allowed to listed users
 	hidden              bool                // optional: if true, view is not shown in menu unless logged in
+	overlay             OverlayConfig       // optional: default Disabled
+	imageFormats        []string            // optional: default jpg; in order of preference, jpg is always appended
+}
+
+type OverlayConfig struct {
+	enabled         bool           // defined automatically if Overlay section exists
+	title           bool           // optional: default True; show the title of the camera
+	timestamp       bool           // optional: default True; show the time the image was fetched
+	timestampFormat string         // optional: default 2006-01-02 15:04:05; a go time layout
+	timeZone        *time.Location // optional: default Local
+	text            string         // optional: default empty; a free text, eg. a copyright notice
+	logo            string         // optional: default empty; path of a png or jpg image
+	position        string         // optional: default bottom-left; top-left, top-right, bottom-left or bottom-right
+	fontSize        int            // optional: default 0 (relative to the image height)
 }
 
 type HttpServerConfig struct {
@@ -68,6 +162,8 @@ type HttpServerConfig struct {
 	bind             string        // optional: defaults to ::1 (ipv6 loopback)
 	port             int           // optional: defaults to 8043
 	logRequests      bool          // optional: default False
+	metrics          bool          // optional: default False; serve prometheus metrics on /metrics
+	readyMinCameras  int           // optional: default 1; how many cameras must have delivered an image for /readyz to succeed
 	frontendProxy    *url.URL      // optional: default deactivated; otherwise an address of the frontend dev-server
 	frontendPath     string        // optional: default "frontend-build"; otherwise set to a path where the frontend build is located
 	frontendExpires  time.Duration // optional: default 5min; what cache-control header to sent for static frontend files
@@ -86,6 +182,7 @@ type configRead struct {
 	Cameras        cameraConfigReadMap     `yaml:"Cameras"`
 	Views          viewConfigReadList      `yaml:"Views"`
 	HttpServer     *httpServerConfigRead   `yaml:"HttpServer"`
+	Archive        *archiveConfigRead      `yaml:"Archive"`
 	LogConfig      *bool                   `yaml:"LogConfig"`
 	LogWorkerStart *bool                   `yaml:"LogWorkerStart"`
 	LogDebug       *bool                   `yaml:"LogDebug"`
@@ -98,6 +195,22 @@ type authConfigRead struct {
 	LogAuth           *bool   `yaml:"LogAuth"`
 }
 
+type archiveConfigRead struct {
+	Directory           string   `yaml:"Directory"`
+	Interval            string   `yaml:"Interval"`
+	MaxAge              string   `yaml:"MaxAge"`
+	MaxSizeMb           *int64   `yaml:"MaxSizeMb"`
+	ResolutionMaxWidth  *int     `yaml:"ResolutionMaxWidth"`
+	ResolutionMaxHeight *int     `yaml:"ResolutionMaxHeight"`
+	JpgQuality          *int     `yaml:"JpgQuality"`
+	Cameras             []string `yaml:"Cameras"`
+
+	TimelapseFps            *int   `yaml:"TimelapseFps"`
+	TimelapseMaxFrames      *int   `yaml:"TimelapseMaxFrames"`
+	TimelapseCacheDirectory string `yaml:"TimelapseCacheDirectory"`
+	TimelapseCacheMaxAge    string `yaml:"TimelapseCacheMaxAge"`
+}
+
 type mqttClientConfigRead struct {
 	Broker            string  `yaml:"Broker"`
 	User              string  `yaml:"User"`
@@ -107,23 +220,92 @@ type mqttClientConfigRead struct {
 	AvailabilityTopic *string `yaml:"AvailabilityTopic"`
 	TopicPrefix       string  `yaml:"TopicPrefix"`
 	LogDebug          *bool   `yaml:"LogDebug"`
+
+	CameraStatusTopic     *string `yaml:"CameraStatusTopic"`
+	CameraImageTopic      *string `yaml:"CameraImageTopic"`
+	CameraCommandTopic    *string `yaml:"CameraCommandTopic"`
+	CameraMotionTopic     *string `yaml:"CameraMotionTopic"`
+	CameraPublishInterval string  `yaml:"CameraPublishInterval"`
+	CameraImageMaxWidth   *int    `yaml:"CameraImageMaxWidth"`
+	CameraImageMaxHeight  *int    `yaml:"CameraImageMaxHeight"`
+	CameraImageJpgQuality *int    `yaml:"CameraImageJpgQuality"`
+
+	HomeAssistantDiscovery       *bool  `yaml:"HomeAssistantDiscovery"`
+	HomeAssistantDiscoveryPrefix string `yaml:"HomeAssistantDiscoveryPrefix"`
+	HomeAssistantNodeId          string `yaml:"HomeAssistantNodeId"`
 }
 
 type mqttClientConfigReadMap map[string]mqttClientConfigRead
 
 type cameraConfigRead struct {
-	Address         string `yaml:"Address"`
-	RefreshInterval string `yaml:"RefreshInterval"`
-	PreemptiveFetch string `yaml:"PreemptiveFetch"`
+	Type             string                    `yaml:"Type"`
+	Address          string                    `yaml:"Address"`
+	User             string                    `yaml:"User"`
+	Password         string                    `yaml:"Password"`
+	Timeout          string                    `yaml:"Timeout"`
+	FileOrder        string                    `yaml:"FileOrder"`
+	ResolutionWidth  *int                      `yaml:"ResolutionWidth"`
+	ResolutionHeight *int                      `yaml:"ResolutionHeight"`
+	RefreshInterval  string                    `yaml:"RefreshInterval"`
+	PreemptiveFetch  string                    `yaml:"PreemptiveFetch"`
+	RecentFrames     *int                      `yaml:"RecentFrames"`
+	MotionDetection  motionDetectionConfigRead `yaml:"MotionDetection"`
+	Transform        transformConfigRead       `yaml:"Transform"`
+}
+
+type transformConfigRead struct {
+	Rotate         *int     `yaml:"Rotate"`
+	Flip           string   `yaml:"Flip"`
+	LensCorrection *float64 `yaml:"LensCorrection"`
+}
+
+type motionDetectionConfigRead struct {
+	Enabled     *bool                `yaml:"Enabled"`
+	GridWidth   *int                 `yaml:"GridWidth"`
+	GridHeight  *int                 `yaml:"GridHeight"`
+	Threshold   *int                 `yaml:"Threshold"`
+	MinArea     *float64             `yaml:"MinArea"`
+	Cooldown    string               `yaml:"Cooldown"`
+	IgnoreMasks regionConfigReadList `yaml:"IgnoreMasks"`
+}
+
+type regionConfigRead struct {
+	Left   float64 `yaml:"Left"`
+	Top    float64 `yaml:"Top"`
+	Width  float64 `yaml:"Width"`
+	Height float64 `yaml:"Height"`
 }
 
+type regionConfigReadList []regionConfigRead
+
 type cameraConfigReadMap map[string]cameraConfigRead
 
 type viewCameraConfigRead struct {
-	Name  string `yaml:"Name"`
-	Title string `yaml:"Title"`
+	Name         string                    `yaml:"Name"`
+	Title        string                    `yaml:"Title"`
+	Source       string                    `yaml:"Source"`
+	Crop         *cropConfigRead           `yaml:"Crop"`
+	PrivacyMasks privacyMaskConfigReadList `yaml:"PrivacyMasks"`
 }
 
+type cropConfigRead struct {
+	Left   float64 `yaml:"Left"`
+	Top    float64 `yaml:"Top"`
+	Width  float64 `yaml:"Width"`
+	Height float64 `yaml:"Height"`
+}
+
+type privacyMaskConfigRead struct {
+	Left    *float64    `yaml:"Left"`
+	Top     *float64    `yaml:"Top"`
+	Width   *float64    `yaml:"Width"`
+	Height  *float64    `yaml:"Height"`
+	Polygon [][]float64 `yaml:"Polygon"`
+	Fill    string      `yaml:"Fill"`
+}
+
+type privacyMaskConfigReadList []privacyMaskConfigRead
+
 type viewCameraConfigReadList []viewCameraConfigRead
 
 type viewConfigRead struct {
@@ -137,6 +319,19 @@ type viewConfigRead struct {
 	Autoplay            *bool                    `yaml:"Autoplay"`
 	AllowedUsers        []string                 `yaml:"AllowedUsers"`
 	Hidden              *bool                    `yaml:"Hidden"`
+	Overlay             *overlayConfigRead       `yaml:"Overlay"`
+	ImageFormats        []string                 `yaml:"ImageFormats"`
+}
+
+type overlayConfigRead struct {
+	Title           *bool  `yaml:"Title"`
+	Timestamp       *bool  `yaml:"Timestamp"`
+	TimestampFormat string `yaml:"TimestampFormat"`
+	TimeZone        string `yaml:"TimeZone"`
+	Text            string `yaml:"Text"`
+	Logo            string `yaml:"Logo"`
+	Position        string `yaml:"Position"`
+	FontSize        *int   `yaml:"FontSize"`
 }
 
 type viewConfigReadList []viewConfigRead
@@ -145,6 +340,8 @@ type httpServerConfigRead struct {
 	Bind             string  `yaml:"Bind"`
 	Port             *int    `yaml:"Port"`
 	LogRequests      *bool   `yaml:"LogRequests"`
+	Metrics          *bool   `yaml:"Metrics"`
+	ReadyMinCameras  *int    `yaml:"ReadyMinCameras"`
 	FrontendProxy    string  `yaml:"FrontendProxy"`
 	FrontendPath     string  `yaml:"FrontendPath"`
 	FrontendExpires  string  `yaml:"FrontendExpires"`
@@ -2,6 +2,7 @@ package hashStore
 
 import (
 	"github.com/koestler/go-webcam/cameraClient"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,10 +12,12 @@ type HashStore struct {
 	shutdown chan struct{}
 	closed   chan struct{}
 
-	setChannel chan setRequest
-	getChannel chan getRequest
+	setChannel  chan setRequest
+	getChannel  chan getRequest
+	pingChannel chan chan struct{}
 
 	storage map[string]value
+	size    atomic.Int64
 }
 
 type Config interface {
@@ -39,12 +42,13 @@ type value struct {
 
 func Run(config Config) *HashStore {
 	h := &HashStore{
-		config:     config,
-		shutdown:   make(chan struct{}),
-		closed:     make(chan struct{}),
-		setChannel: make(chan setRequest, 16),
-		getChannel: make(chan getRequest, 16),
-		storage:    make(map[string]value),
+		config:      config,
+		shutdown:    make(chan struct{}),
+		closed:      make(chan struct{}),
+		setChannel:  make(chan setRequest, 16),
+		getChannel:  make(chan getRequest, 16),
+		pingChannel: make(chan chan struct{}),
+		storage:     make(map[string]value),
 	}
 
 	go h.worker()
@@ -71,6 +75,27 @@ func (h *HashStore) Get(hash string) cameraClient.CameraPicture {
 	return <-response
 }
 
+// Alive returns true when the worker responds within the given timeout.
+func (h *HashStore) Alive(timeout time.Duration) bool {
+	response := make(chan struct{})
+	select {
+	case h.pingChannel <- response:
+	case <-time.After(timeout):
+		return false
+	}
+	select {
+	case <-response:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Size returns the number of stored images.
+func (h *HashStore) Size() int {
+	return int(h.size.Load())
+}
+
 func (h *HashStore) Config() Config {
 	return h.config
 }
@@ -91,6 +116,7 @@ func (h *HashStore) worker() {
 					touched: time.Now(),
 				}
 			}
+			h.size.Store(int64(len(h.storage)))
 			close(setRequest.response)
 		case getRequest := <-h.getChannel:
 			if v, ok := h.storage[getRequest.hash]; ok {
@@ -98,6 +124,8 @@ func (h *HashStore) worker() {
 			} else {
 				getRequest.response <- nil
 			}
+		case response := <-h.pingChannel:
+			close(response)
 		case <-ticker.C:
 			now := time.Now()
 			for k, v := range h.storage {
@@ -105,6 +133,7 @@ func (h *HashStore) worker() {
 					delete(h.storage, k)
 				}
 			}
+			h.size.Store(int64(len(h.storage)))
 		case <-h.shutdown:
 			return // shutdown
 		}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"github.com/koestler/go-webcam/archive"
 	"github.com/koestler/go-webcam/cameraClient"
 	"github.com/koestler/go-webcam/config"
 	"github.com/koestler/go-webcam/hashStore"
 	"github.com/koestler/go-webcam/httpServer"
+	"github.com/koestler/go-webcam/mqttClient"
 	"log"
 )
 
-func runHttpServer(cfg *config.Config, cameraClientPoolInstance *cameraClient.ClientPool) *httpServer.HttpServer {
+func runHttpServer(
+	cfg *config.Config,
+	cameraClientPoolInstance *cameraClient.ClientPool,
+	mqttClientPoolInstance *mqttClient.ClientPool,
+	archiveInstance *archive.Archive,
+	hashStorage *hashStore.HashStore,
+) *httpServer.HttpServer {
 	httpServerCfg := cfg.HttpServer()
 	if !httpServerCfg.Enabled() {
 		return nil
@@ -19,35 +27,52 @@ func runHttpServer(cfg *config.Config, cameraClientPoolInstance *cameraClient.Cl
 	}
 
 	return httpServer.Run(
-		// todo: refactor config and env into one object?
-
-		&httpServer.Environment{
-			Config: httpServerConfig{
-				cfg.HttpServer(),
-				cfg.GetViewNames(),
-				cfg.LogConfig(),
-				cfg.LogDebug(),
-			},
-			ProjectTitle:             cfg.ProjectTitle(),
-			Views:                    cfg.Views(),
-			Auth:                     cfg.Auth(),
-			CameraClientPoolInstance: cameraClientPoolInstance,
-			HashStorage:              hashStore.Run(cfg.HttpServer()),
-		},
+		getHttpServerEnvironment(cfg, cameraClientPoolInstance, mqttClientPoolInstance, archiveInstance, hashStorage),
 	)
 }
 
+func getHttpServerEnvironment(
+	cfg *config.Config,
+	cameraClientPoolInstance *cameraClient.ClientPool,
+	mqttClientPoolInstance *mqttClient.ClientPool,
+	archiveInstance *archive.Archive,
+	hashStorage *hashStore.HashStore,
+) *httpServer.Environment {
+	// todo: refactor config and env into one object?
+	return &httpServer.Environment{
+		Config: httpServerConfig{
+			cfg.HttpServer(),
+			cfg.GetViewNames(),
+			cfg.GetMqttClientNames(),
+			cfg.LogConfig(),
+			cfg.LogDebug(),
+		},
+		ProjectTitle:             cfg.ProjectTitle(),
+		Views:                    cfg.Views(),
+		Auth:                     cfg.Auth(),
+		CameraClientPoolInstance: cameraClientPoolInstance,
+		MqttClientPoolInstance:   mqttClientPoolInstance,
+		HashStorage:              hashStorage,
+		ArchiveInstance:          archiveInstance,
+	}
+}
+
 type httpServerConfig struct {
 	config.HttpServerConfig
-	viewNames []string
-	logConfig bool
-	logDebug  bool
+	viewNames       []string
+	mqttClientNames []string
+	logConfig       bool
+	logDebug        bool
 }
 
 func (c httpServerConfig) GetViewNames() []string {
 	return c.viewNames
 }
 
+func (c httpServerConfig) GetMqttClientNames() []string {
+	return c.mqttClientNames
+}
+
 func (c httpServerConfig) LogConfig() bool {
 	return c.logConfig
 }
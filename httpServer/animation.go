@@ -0,0 +1,88 @@
+package httpServer
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/koestler/go-webcam/config"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultAnimationFrames is used when the frames query parameter is missing.
+const defaultAnimationFrames = 10
+
+// isAnimationRequest returns true when an image route of an animation format is asked for an animation.
+func isAnimationRequest(format string, c *gin.Context) bool {
+	if len(c.Query("frames")) < 1 {
+		return false
+	}
+	for _, f := range cameraClient.AnimationFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func handleCameraAnimation(
+	cache *composedCache,
+	client *cameraClient.Client,
+	view *config.ViewConfig,
+	viewCamera *config.ViewCameraConfig,
+	format string,
+	c *gin.Context,
+	env *Environment,
+) {
+	// check authorization
+	if !isAuthenticated(view, c) {
+		jsonErrorResponse(c, http.StatusForbidden, errors.New("User is not allowed here"))
+		return
+	}
+
+	recentFrames := client.Config().RecentFrames()
+	if recentFrames < 1 {
+		jsonErrorResponse(c, http.StatusNotFound, fmt.Errorf("camera '%s' keeps no recent frames", viewCamera.Source()))
+		return
+	}
+
+	frames := defaultAnimationFrames
+	if f := c.Query("frames"); len(f) > 0 {
+		var err error
+		frames, err = strconv.Atoi(f)
+		if err != nil || frames < 1 {
+			jsonErrorResponse(c, http.StatusBadRequest, fmt.Errorf("frames='%s' must be a positive integer", f))
+			return
+		}
+	}
+	frames = min(frames, recentFrames)
+
+	// make sure the newest frame is fetched according to the refresh interval of the view
+	if newest := client.GetDelayedImage(view.RefreshInterval()); newest.Err() != nil {
+		jsonErrorResponse(c, http.StatusServiceUnavailable, newest.Err())
+		return
+	}
+	recent := client.GetRecentFrames(frames)
+
+	dim := getDimensions(view, c)
+	options := getImageOptions(view, viewCamera)
+	options.Format = format
+	cacheKey := fmt.Sprintf("%s-%d-%s-%s",
+		cameraClient.DimensionCacheKey(dim), view.JpgQuality(), options.CacheKey(),
+		cameraClient.AnimationCacheKey(recent),
+	)
+	animation := cache.get(cacheKey, func() cameraClient.CameraPicture {
+		return cameraClient.ComposeAnimation(recent, dim, view.JpgQuality(), options)
+	})
+	if animation.Err() != nil {
+		jsonErrorResponse(c, http.StatusServiceUnavailable, animation.Err())
+		return
+	}
+
+	if view.IsPublic() {
+		setCacheControlPublicProxy(c, time.Until(animation.Expires())-env.Config.ImageEarlyExpire())
+	}
+	c.Redirect(http.StatusTemporaryRedirect, getImageByHashUrl(animation, env))
+}
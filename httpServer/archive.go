@@ -0,0 +1,274 @@
+package httpServer
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/koestler/go-webcam/archive"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/koestler/go-webcam/config"
+	"github.com/pkg/errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type archiveDatesResponse struct {
+	Dates []string `json:"dates" example:"2022-05-01"`
+}
+
+type archiveTimestampsResponse struct {
+	Date       string      `json:"date" example:"2022-05-01"`
+	Timestamps []time.Time `json:"timestamps"`
+}
+
+// archiveTimeFormats are accepted by the time parameter; times without a zone are interpreted in local time.
+var archiveTimeFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+// setupArchive godoc
+// @Summary Archived camera images
+// @Description Lists the dates and times for which images are archived and returns the archived image
+// @Description taken closest to a given time, scaled to the resolution of the view.
+// @Description These routes only exist for cameras stored by the archive.
+// @ID archive
+// @Param viewName path string true "View Name as provided by the config endpoint"
+// @Param cameraName path string true "Camera Name as provided in Cameras array of the config endpoint"
+// @Produce json
+// @Success 200 {object} archiveDatesResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /archive/{viewName}/{cameraName} [get]
+// @Security ApiKeyAuth
+func setupArchive(r *gin.RouterGroup, env *Environment) {
+	if env.ArchiveInstance == nil {
+		return
+	}
+
+	for _, v := range env.Views {
+		view := v
+		for _, vc := range view.Cameras() {
+			viewCamera := vc
+			if !env.ArchiveInstance.HasCamera(viewCamera.Source()) {
+				continue
+			}
+
+			relativePath := "archive/" + view.Name() + "/" + viewCamera.Name()
+			r.GET(relativePath, func(c *gin.Context) {
+				handleArchiveDates(env.ArchiveInstance, viewCamera, view, c)
+			})
+			r.GET(relativePath+"/:date", func(c *gin.Context) {
+				handleArchiveTimestamps(env.ArchiveInstance, viewCamera, view, c)
+			})
+			r.GET(relativePath+".jpg", func(c *gin.Context) {
+				handleArchiveImage(env.ArchiveInstance, viewCamera, view, c)
+			})
+			for f := range archive.TimelapseFormats {
+				format := f
+				r.GET(relativePath+"."+format, func(c *gin.Context) {
+					handleArchiveTimelapse(env.ArchiveInstance, viewCamera, view, format, c)
+				})
+			}
+			if env.Config.LogConfig() {
+				log.Printf("httpServer: %s%s[/<date>|.jpg|.mp4|.gif|.webp] -> serve archive", r.BasePath(), relativePath)
+			}
+		}
+	}
+}
+
+func handleArchiveDates(a *archive.Archive, viewCamera *config.ViewCameraConfig, view *config.ViewConfig, c *gin.Context) {
+	if !isAuthenticated(view, c) {
+		jsonErrorResponse(c, http.StatusForbidden, errors.New("User is not allowed here"))
+		return
+	}
+
+	dates, err := a.Dates(viewCamera.Source())
+	if err != nil {
+		jsonErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	jsonGetResponse(c, archiveDatesResponse{Dates: dates})
+}
+
+// handleArchiveTimestamps godoc
+// @Summary Archived image times of a day
+// @Description Lists the times of all images of the given camera archived on the given date, oldest first.
+// @ID archiveTimestamps
+// @Param viewName path string true "View Name as provided by the config endpoint"
+// @Param cameraName path string true "Camera Name as provided in Cameras array of the config endpoint"
+// @Param date path string true "Date in the format 2006-01-02"
+// @Produce json
+// @Success 200 {object} archiveTimestampsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /archive/{viewName}/{cameraName}/{date} [get]
+// @Security ApiKeyAuth
+func handleArchiveTimestamps(a *archive.Archive, viewCamera *config.ViewCameraConfig, view *config.ViewConfig, c *gin.Context) {
+	if !isAuthenticated(view, c) {
+		jsonErrorResponse(c, http.StatusForbidden, errors.New("User is not allowed here"))
+		return
+	}
+
+	date := c.Param("date")
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		jsonErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid date: '%s'", date))
+		return
+	}
+
+	timestamps, err := a.Timestamps(viewCamera.Source(), date)
+	if err != nil {
+		jsonErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	jsonGetResponse(c, archiveTimestampsResponse{Date: date, Timestamps: timestamps})
+}
+
+// handleArchiveImage godoc
+// @Summary Archived camera image
+// @Description Returns the archived image taken closest to the given time, scaled to the resolution of the view.
+// @Description The time the image was taken is sent in the X-Image-Time header.
+// @ID archiveImage
+// @Param viewName path string true "View Name as provided by the config endpoint"
+// @Param cameraName path string true "Camera Name as provided in Cameras array of the config endpoint"
+// @Param time query string false "RFC3339 time or local time like 2022-05-01T14:32; default now"
+// @Param width query int false "Downscale image to this width"
+// @Param height query int false "Downscale image to this height"
+// @Produce jpeg
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /archive/{viewName}/{cameraName}.jpg [get]
+// @Security ApiKeyAuth
+func handleArchiveImage(a *archive.Archive, viewCamera *config.ViewCameraConfig, view *config.ViewConfig, c *gin.Context) {
+	if !isAuthenticated(view, c) {
+		jsonErrorResponse(c, http.StatusForbidden, errors.New("User is not allowed here"))
+		return
+	}
+
+	t := time.Now()
+	if str := c.Query("time"); len(str) > 0 {
+		var ok bool
+		if t, ok = parseArchiveTime(str); !ok {
+			jsonErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid time: '%s'", str))
+			return
+		}
+	}
+
+	img, taken, err := a.Closest(viewCamera.Source(), t)
+	if errors.Is(err, archive.ErrNotFound) {
+		jsonErrorResponse(c, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		jsonErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	img, err = cameraClient.ResizeJpg(
+		img, getDimensions(view, c), view.JpgQuality(), getImageOptions(view, viewCamera), taken,
+	)
+	if err != nil {
+		jsonErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("X-Image-Time", taken.Format(time.RFC3339))
+	c.Data(http.StatusOK, "image/jpeg", img)
+}
+
+// handleArchiveTimelapse godoc
+// @Summary Time-lapse video of archived images
+// @Description Renders all archived images taken between from and to into a video using ffmpeg,
+// @Description scaled to the resolution of the view and with its privacy masks and overlay. Rendered videos are cached.
+// @Description Supported formats are mp4, gif and webp.
+// @ID archiveTimelapse
+// @Param viewName path string true "View Name as provided by the config endpoint"
+// @Param cameraName path string true "Camera Name as provided in Cameras array of the config endpoint"
+// @Param format path string true "mp4, gif or webp"
+// @Param from query string false "RFC3339 time or local time like 2022-05-01T06:00; default start of today"
+// @Param to query string false "RFC3339 time or local time like 2022-05-01T20:00; default now"
+// @Param fps query int false "Frames per second; default TimelapseFps of the archive config"
+// @Param width query int false "Downscale video to this width"
+// @Param height query int false "Downscale video to this height"
+// @Produce mpeg
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /archive/{viewName}/{cameraName}.{format} [get]
+// @Security ApiKeyAuth
+func handleArchiveTimelapse(
+	a *archive.Archive,
+	viewCamera *config.ViewCameraConfig,
+	view *config.ViewConfig,
+	format string,
+	c *gin.Context,
+) {
+	if !isAuthenticated(view, c) {
+		jsonErrorResponse(c, http.StatusForbidden, errors.New("User is not allowed here"))
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	to := now
+	for param, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if str := c.Query(param); len(str) > 0 {
+			var ok bool
+			if *t, ok = parseArchiveTime(str); !ok {
+				jsonErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid %s: '%s'", param, str))
+				return
+			}
+		}
+	}
+	if !from.Before(to) {
+		jsonErrorResponse(c, http.StatusBadRequest, errors.New("from must be before to"))
+		return
+	}
+
+	fps := 0
+	if str := c.Query("fps"); len(str) > 0 {
+		var err error
+		if fps, err = strconv.Atoi(str); err != nil || fps < 1 || fps > 120 {
+			jsonErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid fps: '%s'", str))
+			return
+		}
+	}
+
+	dim := getDimensions(view, c)
+	path, err := a.Timelapse(viewCamera.Source(), from, to, archive.TimelapseOptions{
+		Format: format,
+		Fps:    fps,
+		Width:  dim.Width(),
+		Height: dim.Height(),
+
+		ImageOptions: getImageOptions(view, viewCamera),
+	})
+	if errors.Is(err, archive.ErrNotFound) {
+		jsonErrorResponse(c, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		jsonErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Content-Type", archive.TimelapseFormats[format])
+	c.File(path)
+}
+
+func parseArchiveTime(str string) (time.Time, bool) {
+	for _, format := range archiveTimeFormats {
+		if t, err := time.ParseInLocation(format, str, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
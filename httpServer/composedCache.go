@@ -0,0 +1,46 @@
+package httpServer
+
+import (
+	"github.com/koestler/go-webcam/cameraClient"
+	"golang.org/x/sync/singleflight"
+	"sync"
+)
+
+// composedCache holds images composed of several camera images, like mosaics and animations, until they expire.
+// Concurrent requests of the same image compose it once.
+type composedCache struct {
+	mutex   sync.Mutex
+	entries map[string]cameraClient.CameraPicture
+	group   singleflight.Group
+}
+
+func createComposedCache() *composedCache {
+	return &composedCache{entries: make(map[string]cameraClient.CameraPicture)}
+}
+
+// get returns the cached image of the given key or composes it. Failed images are not cached.
+func (cc *composedCache) get(cacheKey string, compose func() cameraClient.CameraPicture) cameraClient.CameraPicture {
+	cc.mutex.Lock()
+	cp, ok := cc.entries[cacheKey]
+	cc.mutex.Unlock()
+	if ok {
+		return cp
+	}
+
+	v, _, _ := cc.group.Do(cacheKey, func() (interface{}, error) {
+		cp := compose()
+
+		cc.mutex.Lock()
+		defer cc.mutex.Unlock()
+		for k, e := range cc.entries {
+			if e.Expired(0) {
+				delete(cc.entries, k)
+			}
+		}
+		if cp.Err() == nil {
+			cc.entries[cacheKey] = cp
+		}
+		return cp, nil
+	})
+	return v.(cameraClient.CameraPicture)
+}
@@ -20,6 +20,7 @@ type viewResponse struct {
 	Autoplay          bool                 `json:"autoplay" example:"True"`
 	IsPublic          bool                 `json:"isPublic" example:"False"`
 	Hidden            bool                 `json:"hidden" example:"False"`
+	ImageFormats      []string             `json:"imageFormats" example:"webp,jpg"`
 }
 
 type cameraViewResponse struct {
@@ -63,6 +64,7 @@ func setupConfig(r *gin.RouterGroup, env *Environment) {
 				Autoplay:          v.Autoplay(),
 				IsPublic:          v.IsPublic(),
 				Hidden:            v.Hidden(),
+				ImageFormats:      v.ImageFormats(),
 			})
 		}
 
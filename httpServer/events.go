@@ -0,0 +1,159 @@
+package httpServer
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/koestler/go-webcam/config"
+	"github.com/pkg/errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+type imageEventResponse struct {
+	Camera   string    `json:"camera" example:"0-cam-east"`
+	ImageUrl string    `json:"imageUrl,omitempty" example:"/api/v0/imagesByHash/0a4d55a8d778e5022fab701977c5d840bbc486d0.jpg"`
+	Fetched  time.Time `json:"fetched"`
+	Expires  time.Time `json:"expires"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// setupEvents godoc
+// @Summary Notifies about new images.
+// @Description Sends Server-Sent Events of type image whenever a new image of a camera of the view is available.
+// @Description Every event contains the imagesByHash url of the new image plus when it was fetched and when
+// @Description the next image is expected. After connecting, one event per camera is sent immediately.
+// @ID events
+// @Param viewName path string true "View Name as provided by the config endpoint"
+// @Param width query int false "Downscale images to this width"
+// @Param height query int false "Downscale images to this height"
+// @Param format query string false "jpg or one of the ImageFormats of the view; default jpg"
+// @Produce text/event-stream
+// @Success 200 {object} imageEventResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /events/{viewName} [get]
+// @Security ApiKeyAuth
+func setupEvents(r *gin.RouterGroup, env *Environment) {
+	for _, v := range env.Views {
+		view := v
+
+		relativePath := "events/" + view.Name()
+		r.GET(relativePath, func(c *gin.Context) {
+			handleEvents(view, c, env)
+		})
+		if env.Config.LogConfig() {
+			log.Printf("httpServer: %s%s -> serve events", r.BasePath(), relativePath)
+		}
+	}
+}
+
+func handleEvents(view *config.ViewConfig, c *gin.Context, env *Environment) {
+	// check authorization
+	if !isAuthenticated(view, c) {
+		jsonErrorResponse(c, http.StatusForbidden, errors.New("User is not allowed here"))
+		return
+	}
+
+	dim := getDimensions(view, c)
+
+	format := cameraClient.ImageFormatJpg
+	if str := c.Query("format"); len(str) > 0 {
+		if !isImageFormatOfView(view, str) {
+			jsonErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid format: '%s'", str))
+			return
+		}
+		format = str
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	events := make(chan imageEventResponse)
+	for _, viewCamera := range view.Cameras() {
+		client := env.CameraClientPoolInstance.GetClient(viewCamera.Source())
+		if client == nil {
+			continue
+		}
+		go watchCameraImages(ctx, cancel, client, view, viewCamera, dim, format, env, events)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			c.SSEvent("image", event)
+			c.Writer.Flush()
+		}
+	}
+}
+
+// watchCameraImages sends an event whenever the image of the camera in the given view changes.
+// It checks for a new image when the current one expires and whenever the camera fetched a new raw image anyway.
+// It ends all events of the request when the camera client is shut down.
+func watchCameraImages(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	client *cameraClient.Client,
+	view *config.ViewConfig,
+	viewCamera *config.ViewCameraConfig,
+	dim Dimension,
+	format string,
+	env *Environment,
+	events chan<- imageEventResponse,
+) {
+	images, unsubscribe := client.SubscribeRawImages()
+	defer unsubscribe()
+
+	options := getImageOptions(view, viewCamera)
+	options.Format = format
+	lastUuid := ""
+	for {
+		// the view shows a new image only once its RefreshInterval has passed; until then the uuid stays the same
+		cameraPicture := client.GetResizedImageWithOptions(view.RefreshInterval(), dim, view.JpgQuality(), options)
+		if isShutdown(cameraPicture) {
+			cancel()
+			return
+		}
+
+		if cameraPicture.Uuid() != lastUuid {
+			lastUuid = cameraPicture.Uuid()
+
+			event := imageEventResponse{
+				Camera:  viewCamera.Name(),
+				Fetched: cameraPicture.Fetched(),
+				Expires: cameraPicture.Expires(),
+			}
+			if err := cameraPicture.Err(); err != nil {
+				event.Error = err.Error()
+			} else {
+				event.ImageUrl = getImageByHashUrl(cameraPicture, env)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case events <- event:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-client.Done():
+			cancel()
+			return
+		case <-images:
+		case <-time.After(nextImageDelay(cameraPicture, view)):
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package httpServer
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/koestler/go-webcam/cameraClient"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const healthTimeout = 5 * time.Second
+
+type healthResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// setupHealth godoc
+// @Summary Liveness and readiness probes
+// @Description /healthz succeeds when all worker go routines respond and no camera fetch is stuck.
+// @Description /readyz succeeds when at least ReadyMinCameras cameras delivered an image
+// @Description and all configured mqtt clients are connected.
+// @ID health
+// @Produce json
+// @Success 200 {object} healthResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /healthz [get]
+// @Router /readyz [get]
+func setupHealth(engine *gin.Engine, env *Environment) {
+	engine.GET("/healthz", func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		if err := checkLiveness(env); err != nil {
+			jsonErrorResponse(c, http.StatusServiceUnavailable, err)
+			return
+		}
+		c.JSON(http.StatusOK, healthResponse{Status: "ok"})
+	})
+
+	engine.GET("/readyz", func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		if err := checkReadiness(env); err != nil {
+			jsonErrorResponse(c, http.StatusServiceUnavailable, err)
+			return
+		}
+		c.JSON(http.StatusOK, healthResponse{Status: "ok"})
+	})
+
+	if env.Config.LogConfig() {
+		log.Printf("httpServer: /healthz -> serve liveness probe")
+		log.Printf("httpServer: /readyz -> serve readiness probe")
+	}
+}
+
+// checkLiveness probes all camera clients and the hash store in parallel such that the probe takes at most
+// healthTimeout regardless of the number of cameras.
+func checkLiveness(env *Environment) error {
+	clients := env.CameraClientPoolInstance.GetClients()
+	errs := make(chan error, len(clients)+1)
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(client *cameraClient.Client) {
+			defer wg.Done()
+			if !client.Alive(healthTimeout) {
+				errs <- fmt.Errorf("cameraClient[%s] is not responding", client.Name())
+			}
+		}(c)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if !env.HashStorage.Alive(healthTimeout) {
+			errs <- fmt.Errorf("hashStore is not responding")
+		}
+	}()
+	wg.Wait()
+	close(errs)
+
+	// return the first error, if any
+	return <-errs
+}
+
+func checkReadiness(env *Environment) error {
+	clients := env.CameraClientPoolInstance.GetClients()
+	minCameras := env.Config.ReadyMinCameras()
+	if countReadyCameras(clients) < minCameras {
+		// cameras only fetch on demand; without probing, a failed startup fetch would keep the server unready
+		probeCameras(clients, healthTimeout)
+		if countReady := countReadyCameras(clients); countReady < minCameras {
+			return fmt.Errorf("only %d of %d required cameras delivered an image", countReady, minCameras)
+		}
+	}
+
+	for _, name := range env.Config.GetMqttClientNames() {
+		client := env.MqttClientPoolInstance.GetClient(name)
+		if client == nil || !client.IsConnected() {
+			return fmt.Errorf("mqttClient[%s] is not connected", name)
+		}
+	}
+
+	return nil
+}
+
+func countReadyCameras(clients []*cameraClient.Client) (countReady int) {
+	for _, client := range clients {
+		if !client.Stats().LastSuccess.IsZero() {
+			countReady += 1
+		}
+	}
+	return
+}
+
+// probeCameras requests an image of all cameras which never delivered one and waits for at most timeout.
+// The cache of the camera client limits the fetches to one per RefreshInterval.
+func probeCameras(clients []*cameraClient.Client, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		if !c.Stats().LastSuccess.IsZero() {
+			continue
+		}
+		wg.Add(1)
+		go func(client *cameraClient.Client) {
+			defer wg.Done()
+			client.GetRawImage()
+		}(c)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
@@ -4,19 +4,23 @@ import (
 	"context"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
+	"github.com/koestler/go-webcam/archive"
 	"github.com/koestler/go-webcam/cameraClient"
 	"github.com/koestler/go-webcam/config"
 	"github.com/koestler/go-webcam/hashStore"
+	"github.com/koestler/go-webcam/mqttClient"
 	"log"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
 type HttpServer struct {
 	config Config
 	server *http.Server
+	engine atomic.Pointer[gin.Engine]
 }
 
 type Environment struct {
@@ -25,7 +29,9 @@ type Environment struct {
 	Views                    []*config.ViewConfig
 	Auth                     config.AuthConfig
 	CameraClientPoolInstance *cameraClient.ClientPool
+	MqttClientPoolInstance   *mqttClient.ClientPool
 	HashStorage              *hashStore.HashStore
+	ArchiveInstance          *archive.Archive // nil when the archive is disabled
 }
 
 type Config interface {
@@ -33,11 +39,14 @@ type Config interface {
 	Bind() string
 	Port() int
 	LogRequests() bool
+	Metrics() bool
+	ReadyMinCameras() int
 	LogDebug() bool
 	LogConfig() bool
 	FrontendProxy() *url.URL
 	FrontendPath() string
 	GetViewNames() []string
+	GetMqttClientNames() []string
 	FrontendExpires() time.Duration
 	ConfigExpires() time.Duration
 	ImageEarlyExpire() time.Duration
@@ -48,21 +57,17 @@ func Run(env *Environment) (httpServer *HttpServer) {
 	config := env.Config
 
 	gin.SetMode("release")
-	engine := gin.New()
-	if config.LogRequests() {
-		engine.Use(gin.Logger())
-	}
-	engine.Use(gin.Recovery())
-	engine.Use(gzip.Gzip(gzip.BestCompression))
-	engine.Use(authJwtMiddleware(env))
 
-	addApiV0Routes(engine, config, env)
-	setupFrontend(engine, config)
+	httpServer = &HttpServer{
+		config: config,
+	}
+	httpServer.engine.Store(newEngine(env))
 
 	server := &http.Server{
 		Addr:    config.Bind() + ":" + strconv.Itoa(config.Port()),
-		Handler: engine,
+		Handler: httpServer,
 	}
+	httpServer.server = server
 
 	go func() {
 		if config.LogDebug() {
@@ -73,10 +78,55 @@ func Run(env *Environment) (httpServer *HttpServer) {
 		}
 	}()
 
-	return &HttpServer{
-		config: config,
-		server: server,
+	return
+}
+
+// Reload builds all routes from the given environment and atomically replaces the running ones.
+// Requests already in progress are completed using the old routes.
+// Bind and Port cannot be changed without a restart.
+func (s *HttpServer) Reload(env *Environment) {
+	config := env.Config
+	if config.Bind() != s.config.Bind() || config.Port() != s.config.Port() {
+		log.Printf("httpServer: reload: changing Bind or Port requires a restart; keep listening on %s", s.server.Addr)
 	}
+
+	s.engine.Store(newEngine(env))
+
+	if config.LogDebug() {
+		log.Printf("httpServer: reload completed")
+	}
+}
+
+func (s *HttpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.engine.Load().ServeHTTP(w, r)
+}
+
+func newEngine(env *Environment) *gin.Engine {
+	config := env.Config
+
+	engine := gin.New()
+	if config.LogRequests() {
+		engine.Use(gin.Logger())
+	}
+	engine.Use(gin.Recovery())
+	engine.Use(gzip.Gzip(
+		gzip.BestCompression,
+		// streams are never compressed; they would be buffered by the compressor
+		gzip.WithExcludedPaths([]string{"/api/v0/stream/", "/api/v0/events/"}),
+		// images and videos are already compressed
+		gzip.WithExcludedExtensions([]string{".png", ".gif", ".jpeg", ".jpg", ".mp4", ".webp", ".avif"}),
+	))
+	engine.Use(authJwtMiddleware(env))
+
+	if config.Metrics() {
+		setupMetrics(engine, env)
+	}
+
+	setupHealth(engine, env)
+	addApiV0Routes(engine, config, env)
+	setupFrontend(engine, config)
+
+	return engine
 }
 
 func (s *HttpServer) Shutdown() {
@@ -94,4 +144,9 @@ func addApiV0Routes(r *gin.Engine, config Config, env *Environment) {
 	setupLogin(v0, env)
 	setupImagesByHash(v0, env)
 	setupImages(v0, env)
+	setupMosaic(v0, env)
+	setupStream(v0, env)
+	setupEvents(v0, env)
+	setupStatus(v0, env)
+	setupArchive(v0, env)
 }
@@ -8,45 +8,76 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // setupImages godoc
 // @Summary Outputs camera images.
 // @Description Fetches the images from the camera (or from a cache), scales it to the requested resolution
-// @Description and then returns it.
+// @Description and then returns it. Besides jpg, a view can offer webp and avif using ImageFormats.
+// @Description The jpg route then returns the first of these formats listed in the Accept header.
+// @Description The gif route, and the webp route when frames is given, return an animation of the most
+// @Description recent frames of the camera, limited by its RecentFrames setting.
 // @ID images
 // @Param viewName path string true "View Name as provided by the config endpoint"
 // @Param cameraName path string true "Camera Name as provided in Cameras array of the config endpoint"
 // @Param width query int false "Downscale image to this width"
 // @Param height query int false "Downscale image to this height"
+// @Param format path string true "jpg, gif or one of the ImageFormats of the view"
+// @Param frames query int false "Number of frames of the animation; default 10"
 // @Produce jpeg
+// @Produce webp
+// @Produce avif
+// @Produce gif
 // @Success 200
 // @Success 307
+// @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /images/{viewName}/{cameraName}.jpg [get]
+// @Router /images/{viewName}/{cameraName}.{format} [get]
 // @Security ApiKeyAuth
 func setupImages(r *gin.RouterGroup, env *Environment) {
+	animations := createComposedCache()
+
 	// add dynamic routes
 	for _, v := range env.Views {
 		view := v
-		for _, c := range view.CameraNames() {
-			camera := c
+		for _, vc := range view.Cameras() {
+			viewCamera := vc
 
-			client := env.CameraClientPoolInstance.GetClient(camera)
+			client := env.CameraClientPoolInstance.GetClient(viewCamera.Source())
 			if client == nil {
 				continue
 			}
 
-			relativePath := "images/" + view.Name() + "/" + camera + ".jpg"
-			r.GET(relativePath, func(c *gin.Context) {
-				handleCameraImage(client, view, c, env)
+			relativePath := "images/" + view.Name() + "/" + viewCamera.Name()
+			for _, f := range view.ImageFormats() {
+				format := f
+				r.GET(relativePath+"."+format, func(c *gin.Context) {
+					if isAnimationRequest(format, c) {
+						handleCameraAnimation(animations, client, view, viewCamera, format, c, env)
+						return
+					}
+					if format != cameraClient.ImageFormatJpg || len(view.ImageFormats()) < 2 {
+						handleCameraImage(client, view, viewCamera, format, c, env)
+						return
+					}
+					// the jpg route is used by existing clients; serve the preferred format they accept
+					c.Header("Vary", "Accept")
+					handleCameraImage(client, view, viewCamera, negotiateImageFormat(view, c), c, env)
+				})
+			}
+			r.GET(relativePath+".gif", func(c *gin.Context) {
+				handleCameraAnimation(animations, client, view, viewCamera, "gif", c, env)
 			})
 			if env.Config.LogConfig() {
-				log.Printf("httpServer: %s%s -> serve image", r.BasePath(), relativePath)
+				log.Printf("httpServer: %s%s.<%s> -> serve image",
+					r.BasePath(), relativePath, strings.Join(view.ImageFormats(), "|"),
+				)
+				log.Printf("httpServer: %s%s.gif -> serve animation", r.BasePath(), relativePath)
 			}
 		}
 	}
@@ -55,6 +86,8 @@ func setupImages(r *gin.RouterGroup, env *Environment) {
 func handleCameraImage(
 	cameraClient *cameraClient.Client,
 	view *config.ViewConfig,
+	viewCamera *config.ViewCameraConfig,
+	format string,
 	c *gin.Context,
 	env *Environment,
 ) {
@@ -65,9 +98,11 @@ func handleCameraImage(
 	}
 
 	// fetch image
-	cameraPicture := cameraClient.GetResizedImage(
+	options := getImageOptions(view, viewCamera)
+	options.Format = format
+	cameraPicture := cameraClient.GetResizedImageWithOptions(
 		view.RefreshInterval(), getDimensions(view, c),
-		view.JpgQuality(),
+		view.JpgQuality(), options,
 	)
 
 	// handle camera fetching errors
@@ -117,6 +152,81 @@ func getDimensions(view *config.ViewConfig, c *gin.Context) (dim Dimension) {
 	return
 }
 
+// getImageOptions returns the processing options of the resize stage configured by the view and the view camera.
+func getImageOptions(view *config.ViewConfig, viewCamera *config.ViewCameraConfig) (options cameraClient.ImageOptions) {
+	if crop := viewCamera.Crop(); crop.Enabled() {
+		options.Crop = &cameraClient.Region{
+			Left:   crop.Left(),
+			Top:    crop.Top(),
+			Width:  crop.Width(),
+			Height: crop.Height(),
+		}
+	}
+
+	for _, mask := range viewCamera.PrivacyMasks() {
+		options.PrivacyMasks = append(options.PrivacyMasks, cameraClient.PrivacyMask{
+			Polygon: mask.Polygon(),
+			Blur:    mask.Fill() == "blur",
+		})
+	}
+
+	if overlay := view.Overlay(); overlay.Enabled() {
+		options.Overlay = &cameraClient.Overlay{
+			Text:     overlay.Text(),
+			Logo:     overlay.Logo(),
+			Position: overlay.Position(),
+			FontSize: overlay.FontSize(),
+			Location: overlay.TimeZone(),
+		}
+		if overlay.Title() {
+			options.Overlay.Title = viewCamera.Title()
+		}
+		if overlay.Timestamp() {
+			options.Overlay.TimestampFormat = overlay.TimestampFormat()
+		}
+	}
+	return
+}
+
+// negotiateImageFormat returns the first of the formats of the view which is accepted by the client.
+func negotiateImageFormat(view *config.ViewConfig, c *gin.Context) string {
+	accept := c.GetHeader("Accept")
+	for _, format := range view.ImageFormats() {
+		if format == cameraClient.ImageFormatJpg || acceptsContentType(accept, cameraClient.ImageFormats[format]) {
+			return format
+		}
+	}
+	return cameraClient.ImageFormatJpg
+}
+
+func isImageFormatOfView(view *config.ViewConfig, format string) bool {
+	for _, f := range view.ImageFormats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsContentType returns true if the Accept header explicitly lists the content type with a quality above zero.
+func acceptsContentType(accept, contentType string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		if strings.TrimSpace(params[0]) != contentType {
+			continue
+		}
+		for _, param := range params[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil && v <= 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
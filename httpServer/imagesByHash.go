@@ -17,12 +17,16 @@ import (
 // @Description Returns an image of which the hash is known.
 // @ID imagesByHash
 // @Param hash path string true "The hash of the image properties."
+// @Param format path string true "jpg, webp, avif or gif; as given by the url of the image"
 // @Produce jpeg
+// @Produce webp
+// @Produce avif
+// @Produce gif
 // @Success 200
 // @Failure 500 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /imagesByHash/{hash}.jpg [get]
+// @Router /imagesByHash/{hash}.{format} [get]
 // @Security ApiKeyAuth
 func setupImagesByHash(r *gin.RouterGroup, env *Environment) {
 	r.GET("imagesByHash/:filename", func(c *gin.Context) {
@@ -33,31 +37,31 @@ func setupImagesByHash(r *gin.RouterGroup, env *Environment) {
 		}
 		hash := filename[0:40]
 		cp := env.HashStorage.Get(hash)
-		if cp == nil {
+		if cp == nil || filename[41:] != cp.Format() {
 			jsonErrorResponse(c, http.StatusNotFound, fmt.Errorf("unknown hash: '%s", hash))
 			return
 		}
 
 		setCacheControlPublic(c, time.Until(cp.Expires().Add(env.HashStorage.Config().HashTimeout())))
 		c.Header("X-Next-Image-At", cp.Expires().Format(time.RFC3339Nano))
-		c.Data(http.StatusOK, "image/jpeg", cp.JpgImg())
+		c.Data(http.StatusOK, cameraClient.ImageFormats[cp.Format()], cp.Img())
 	})
 	if env.Config.LogConfig() {
-		log.Printf("httpServer: %simagesByHash/<hash>.jpg -> serve imagesByHash", r.BasePath())
+		log.Printf("httpServer: %simagesByHash/<hash>.<jpg|webp|avif|gif> -> serve imagesByHash", r.BasePath())
 	}
 }
 
-var hashFileNameMatcher = regexp.MustCompilePOSIX(`^[0-9a-f]{40}\.jpg$`)
+var hashFileNameMatcher = regexp.MustCompilePOSIX(`^[0-9a-f]{40}\.(jpg|webp|avif|gif)$`)
 
 func getImageByHashUrl(cp cameraClient.CameraPicture, env *Environment) string {
 	hash := getHash(cp, env.Config.HashSecret())
 	env.HashStorage.Set(hash, cp)
-	return fmt.Sprintf("/api/v0/imagesByHash/%s.jpg", hash)
+	return fmt.Sprintf("/api/v0/imagesByHash/%s.%s", hash, cp.Format())
 }
 
 func getHash(cp cameraClient.CameraPicture, hashSecret string) string {
 	dimKey := cameraClient.DimensionCacheKey(cameraClient.DimensionOfImage(cp.DecodedImg()))
-	str := fmt.Sprintf("%s-%s-%s", hashSecret, cp.Uuid(), dimKey)
+	str := fmt.Sprintf("%s-%s-%s-%s", hashSecret, cp.Uuid(), dimKey, cp.Variant())
 	h := sha1.New()
 	h.Write([]byte(str))
 	return hex.EncodeToString(h.Sum(nil))
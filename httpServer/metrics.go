@@ -0,0 +1,172 @@
+package httpServer
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"log"
+	"strconv"
+	"time"
+)
+
+const metricsNamespace = "gowebcam"
+
+var (
+	cameraFetchesDesc = prometheus.NewDesc(
+		metricsNamespace+"_camera_fetches_total",
+		"Number of images fetched from the camera, including failed fetches.",
+		[]string{"camera"}, nil,
+	)
+	cameraFetchErrorsDesc = prometheus.NewDesc(
+		metricsNamespace+"_camera_fetch_errors_total",
+		"Number of failed image fetches.",
+		[]string{"camera"}, nil,
+	)
+	cameraFetchDurationDesc = prometheus.NewDesc(
+		metricsNamespace+"_camera_fetch_duration_seconds",
+		"Time taken to fetch and decode an image from the camera.",
+		[]string{"camera"}, nil,
+	)
+	cameraImageAgeDesc = prometheus.NewDesc(
+		metricsNamespace+"_camera_image_age_seconds",
+		"Time since the last successful fetch.",
+		[]string{"camera"}, nil,
+	)
+	cameraMotionEventsDesc = prometheus.NewDesc(
+		metricsNamespace+"_camera_motion_events_total",
+		"Number of times motion was detected; only for cameras with motion detection enabled.",
+		[]string{"camera"}, nil,
+	)
+	cameraMotionActiveDesc = prometheus.NewDesc(
+		metricsNamespace+"_camera_motion_active",
+		"1 while motion is detected, 0 otherwise; only for cameras with motion detection enabled.",
+		[]string{"camera"}, nil,
+	)
+	cacheHitsDesc = prometheus.NewDesc(
+		metricsNamespace+"_cache_hits_total",
+		"Number of cache hits per camera and cache stage.",
+		[]string{"camera", "stage"}, nil,
+	)
+	cacheMissesDesc = prometheus.NewDesc(
+		metricsNamespace+"_cache_misses_total",
+		"Number of cache misses per camera and cache stage.",
+		[]string{"camera", "stage"}, nil,
+	)
+	cacheEntriesDesc = prometheus.NewDesc(
+		metricsNamespace+"_cache_entries",
+		"Number of images currently cached per camera and cache stage.",
+		[]string{"camera", "stage"}, nil,
+	)
+	hashStoreEntriesDesc = prometheus.NewDesc(
+		metricsNamespace+"_hash_store_entries",
+		"Number of images currently available by hash.",
+		nil, nil,
+	)
+)
+
+// cameraCollector reads the counters of all camera clients and the hash store whenever metrics are scraped.
+type cameraCollector struct {
+	env *Environment
+}
+
+func (cc cameraCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cameraFetchesDesc
+	ch <- cameraFetchErrorsDesc
+	ch <- cameraFetchDurationDesc
+	ch <- cameraImageAgeDesc
+	ch <- cameraMotionEventsDesc
+	ch <- cameraMotionActiveDesc
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheEntriesDesc
+	ch <- hashStoreEntriesDesc
+}
+
+func (cc cameraCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, client := range cc.env.CameraClientPoolInstance.GetClients() {
+		name := client.Name()
+		stats := client.Stats()
+
+		ch <- prometheus.MustNewConstMetric(cameraFetchesDesc, prometheus.CounterValue, float64(stats.FetchCount), name)
+		ch <- prometheus.MustNewConstMetric(cameraFetchErrorsDesc, prometheus.CounterValue, float64(stats.FetchErrors), name)
+
+		buckets := make(map[float64]uint64, len(cameraClient.FetchDurationBuckets))
+		var cumulative uint64
+		for i, upperBound := range cameraClient.FetchDurationBuckets {
+			cumulative += stats.FetchDurationBuckets[i]
+			buckets[upperBound] = cumulative
+		}
+		ch <- prometheus.MustNewConstHistogram(
+			cameraFetchDurationDesc, stats.FetchCount, stats.FetchDurationSum.Seconds(), buckets, name,
+		)
+
+		if !stats.LastSuccess.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				cameraImageAgeDesc, prometheus.GaugeValue, time.Since(stats.LastSuccess).Seconds(), name,
+			)
+		}
+
+		if client.Config().MotionDetection().Enabled() {
+			active := 0.0
+			if stats.MotionActive {
+				active = 1
+			}
+			ch <- prometheus.MustNewConstMetric(cameraMotionEventsDesc, prometheus.CounterValue, float64(stats.MotionEvents), name)
+			ch <- prometheus.MustNewConstMetric(cameraMotionActiveDesc, prometheus.GaugeValue, active, name)
+		}
+
+		for _, stage := range cameraClient.Stages {
+			ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.CacheHits[stage]), name, stage)
+			ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.CacheMisses[stage]), name, stage)
+			ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(stats.CacheEntries[stage]), name, stage)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(hashStoreEntriesDesc, prometheus.GaugeValue, float64(cc.env.HashStorage.Size()))
+}
+
+// setupMetrics serves metrics in the prometheus exposition format on /metrics
+// and adds a middleware counting all requests per route.
+func setupMetrics(engine *gin.Engine, env *Environment) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		cameraCollector{env},
+	)
+
+	httpRequests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_requests_total",
+		Help:      "Number of http requests per route, method and status code.",
+	}, []string{"route", "method", "code"})
+	httpRequestDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve http requests per route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	registry.MustRegister(httpRequests, httpRequestDuration)
+
+	engine.Use(func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if len(route) < 1 {
+			route = "unmatched"
+		}
+		httpRequests.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+		httpRequestDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+	})
+
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	engine.GET("/metrics", func(c *gin.Context) {
+		handler.ServeHTTP(c.Writer, c.Request)
+	})
+	if env.Config.LogConfig() {
+		log.Printf("httpServer: /metrics -> serve prometheus metrics")
+	}
+}
@@ -0,0 +1,111 @@
+package httpServer
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/koestler/go-webcam/config"
+	"github.com/pkg/errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// setupMosaic godoc
+// @Summary Mosaic of all cameras of a view
+// @Description Composes the images of all cameras of the view into a single grid image with a title label per tile.
+// @Description The mosaic fits into the resolution of the view. Like single images, it is served by a redirect
+// @Description to the imagesByHash endpoint.
+// @ID mosaic
+// @Param viewName path string true "View Name as provided by the config endpoint"
+// @Param width query int false "Downscale mosaic to this width"
+// @Param height query int false "Downscale mosaic to this height"
+// @Produce jpeg
+// @Success 307
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /mosaic/{viewName}.jpg [get]
+// @Security ApiKeyAuth
+func setupMosaic(r *gin.RouterGroup, env *Environment) {
+	cache := createComposedCache()
+
+	for _, v := range env.Views {
+		view := v
+
+		relativePath := "mosaic/" + view.Name() + ".jpg"
+		r.GET(relativePath, func(c *gin.Context) {
+			handleMosaic(cache, view, c, env)
+		})
+		if env.Config.LogConfig() {
+			log.Printf("httpServer: %s%s -> serve mosaic", r.BasePath(), relativePath)
+		}
+	}
+}
+
+func handleMosaic(cache *composedCache, view *config.ViewConfig, c *gin.Context, env *Environment) {
+	if !isAuthenticated(view, c) {
+		jsonErrorResponse(c, http.StatusForbidden, errors.New("User is not allowed here"))
+		return
+	}
+
+	tiles := getMosaicTiles(view, getDimensions(view, c), env)
+	if len(tiles) < 1 {
+		jsonErrorResponse(c, http.StatusServiceUnavailable, errors.New("no camera available"))
+		return
+	}
+
+	cacheKey := view.Name() + "-" + cameraClient.MosaicCacheKey(tiles)
+	mosaic := cache.get(cacheKey, func() cameraClient.CameraPicture {
+		return cameraClient.ComposeMosaic(tiles, view.JpgQuality())
+	})
+	if mosaic.Err() != nil {
+		jsonErrorResponse(c, http.StatusServiceUnavailable, mosaic.Err())
+		return
+	}
+
+	if view.IsPublic() {
+		setCacheControlPublicProxy(c, time.Until(mosaic.Expires())-env.Config.ImageEarlyExpire())
+	}
+	c.Redirect(http.StatusTemporaryRedirect, getImageByHashUrl(mosaic, env))
+}
+
+// getMosaicTiles fetches the images of all cameras of the view in parallel, each scaled to fit into its grid cell.
+func getMosaicTiles(view *config.ViewConfig, dim Dimension, env *Environment) []cameraClient.MosaicTile {
+	type viewCameraClient struct {
+		viewCamera *config.ViewCameraConfig
+		client     *cameraClient.Client
+	}
+	var cameras []viewCameraClient
+	for _, viewCamera := range view.Cameras() {
+		if client := env.CameraClientPoolInstance.GetClient(viewCamera.Source()); client != nil {
+			cameras = append(cameras, viewCameraClient{viewCamera, client})
+		}
+	}
+
+	columns, rows := cameraClient.MosaicGrid(len(cameras))
+	if columns < 1 {
+		return nil
+	}
+	tileDim := Dimension{
+		width:  max(1, dim.Width()/columns),
+		height: max(1, dim.Height()/rows),
+	}
+
+	tiles := make([]cameraClient.MosaicTile, len(cameras))
+	var wg sync.WaitGroup
+	for i, camera := range cameras {
+		wg.Add(1)
+		go func(i int, viewCamera *config.ViewCameraConfig, client *cameraClient.Client) {
+			defer wg.Done()
+			tiles[i] = cameraClient.MosaicTile{
+				Title: viewCamera.Title(),
+				Picture: client.GetResizedImageWithOptions(
+					view.RefreshInterval(), tileDim, view.JpgQuality(), getImageOptions(view, viewCamera),
+				),
+			}
+		}(i, camera.viewCamera, camera.client)
+	}
+	wg.Wait()
+
+	return tiles
+}
@@ -0,0 +1,112 @@
+package httpServer
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"time"
+)
+
+type statusResponse struct {
+	Cameras []cameraStatusResponse `json:"cameras"`
+}
+
+type cameraStatusResponse struct {
+	Name                   string     `json:"name" example:"0-cam-east"`
+	LastFetch              *time.Time `json:"lastFetch"`
+	LastSuccess            *time.Time `json:"lastSuccess"`
+	LastFailure            *time.Time `json:"lastFailure"`
+	LastError              string     `json:"lastError,omitempty" example:"exit status 1"`
+	Healthy                bool       `json:"healthy" example:"True"`
+	ConsecutiveFailures    uint64     `json:"consecutiveFailures" example:"0"`
+	FetchCount             uint64     `json:"fetchCount" example:"42"`
+	AverageFetchDurationMs int64      `json:"averageFetchDurationMs" example:"530"`
+	Width                  int        `json:"width" example:"1920"`
+	Height                 int        `json:"height" example:"1080"`
+	PreemptiveFetchRunning bool       `json:"preemptiveFetchRunning" example:"False"`
+	// only set when motion detection is enabled for this camera
+	Motion *motionStatusResponse `json:"motion,omitempty"`
+}
+
+type motionStatusResponse struct {
+	Active    bool       `json:"active" example:"False"`
+	Events    uint64     `json:"events" example:"3"`
+	LastStart *time.Time `json:"lastStart"`
+	LastEnd   *time.Time `json:"lastEnd"`
+	LastArea  float64    `json:"lastArea" example:"4.5"`
+}
+
+// setupStatus godoc
+// @Summary Camera health status
+// @Description Lists the fetch statistics of all cameras visible to the current user,
+// @Description i.e. all cameras of all views the user is allowed to access.
+// @ID status
+// @Produce json
+// @Success 200 {object} statusResponse
+// @Router /status [get]
+// @Security ApiKeyAuth
+func setupStatus(r *gin.RouterGroup, env *Environment) {
+	r.GET("status", func(c *gin.Context) {
+		// collect the names of all cameras of all views the user is allowed to see
+		visible := make(map[string]struct{})
+		for _, v := range env.Views {
+			if !isAuthenticated(v, c) {
+				continue
+			}
+			for _, camera := range v.Cameras() {
+				visible[camera.Source()] = struct{}{}
+			}
+		}
+
+		response := statusResponse{
+			Cameras: make([]cameraStatusResponse, 0, len(visible)),
+		}
+		for _, client := range env.CameraClientPoolInstance.GetClients() {
+			if _, ok := visible[client.Name()]; !ok {
+				continue
+			}
+
+			stats := client.Stats()
+			cs := cameraStatusResponse{
+				Name:                   client.Name(),
+				LastFetch:              optionalTime(stats.LastFetch),
+				LastSuccess:            optionalTime(stats.LastSuccess),
+				LastFailure:            optionalTime(stats.LastFailure),
+				Healthy:                stats.FetchCount > 0 && stats.ConsecutiveFailures == 0,
+				ConsecutiveFailures:    stats.ConsecutiveFailures,
+				FetchCount:             stats.FetchCount,
+				Width:                  stats.LastSuccessDimension.Width(),
+				Height:                 stats.LastSuccessDimension.Height(),
+				PreemptiveFetchRunning: stats.PreemptiveFetchRunning,
+			}
+			if client.Config().MotionDetection().Enabled() {
+				cs.Motion = &motionStatusResponse{
+					Active:    stats.MotionActive,
+					Events:    stats.MotionEvents,
+					LastStart: optionalTime(stats.LastMotionStart),
+					LastEnd:   optionalTime(stats.LastMotionEnd),
+					LastArea:  stats.LastMotionArea,
+				}
+			}
+			if stats.LastErr != nil {
+				cs.LastError = stats.LastErr.Error()
+			}
+			if stats.FetchCount > 0 {
+				cs.AverageFetchDurationMs = (stats.FetchDurationSum / time.Duration(stats.FetchCount)).Milliseconds()
+			}
+			response.Cameras = append(response.Cameras, cs)
+		}
+
+		c.Header("Cache-Control", "no-store")
+		jsonGetResponse(c, response)
+	})
+	if env.Config.LogConfig() {
+		log.Printf("httpServer: %sstatus -> serve status", r.BasePath())
+	}
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,137 @@
+package httpServer
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/koestler/go-webcam/config"
+	"github.com/pkg/errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+const streamBoundary = "go-webcam-frame"
+
+// setupStream godoc
+// @Summary Outputs a live stream of camera images.
+// @Description Sends a multipart/x-mixed-replace (MJPEG) stream. A new part is pushed whenever a new image
+// @Description is available, at most once per RefreshInterval of the view.
+// @ID stream
+// @Param viewName path string true "View Name as provided by the config endpoint"
+// @Param cameraName path string true "Camera Name as provided in Cameras array of the config endpoint"
+// @Param width query int false "Downscale image to this width"
+// @Param height query int false "Downscale image to this height"
+// @Produce multipart/x-mixed-replace
+// @Success 200
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /stream/{viewName}/{cameraName}.mjpg [get]
+// @Security ApiKeyAuth
+func setupStream(r *gin.RouterGroup, env *Environment) {
+	// add dynamic routes
+	for _, v := range env.Views {
+		view := v
+		for _, vc := range view.Cameras() {
+			viewCamera := vc
+
+			client := env.CameraClientPoolInstance.GetClient(viewCamera.Source())
+			if client == nil {
+				continue
+			}
+
+			relativePath := "stream/" + view.Name() + "/" + viewCamera.Name() + ".mjpg"
+			r.GET(relativePath, func(c *gin.Context) {
+				handleCameraStream(client, view, viewCamera, c)
+			})
+			if env.Config.LogConfig() {
+				log.Printf("httpServer: %s%s -> serve stream", r.BasePath(), relativePath)
+			}
+		}
+	}
+}
+
+func handleCameraStream(
+	cameraClient *cameraClient.Client,
+	view *config.ViewConfig,
+	viewCamera *config.ViewCameraConfig,
+	c *gin.Context,
+) {
+	// check authorization
+	if !isAuthenticated(view, c) {
+		jsonErrorResponse(c, http.StatusForbidden, errors.New("User is not allowed here"))
+		return
+	}
+
+	dim := getDimensions(view, c)
+	options := getImageOptions(view, viewCamera)
+
+	// fetch first image; fail early when the camera is not available
+	cameraPicture := cameraClient.GetResizedImageWithOptions(view.RefreshInterval(), dim, view.JpgQuality(), options)
+	if cameraPicture.Err() != nil {
+		jsonErrorResponse(c, http.StatusServiceUnavailable, cameraPicture.Err())
+		return
+	}
+
+	c.Header("Content-Type", "multipart/x-mixed-replace; boundary="+streamBoundary)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	lastUuid := ""
+	for {
+		if cameraPicture.Err() == nil && cameraPicture.Uuid() != lastUuid {
+			if err := writeStreamPart(c, cameraPicture); err != nil {
+				return
+			}
+			lastUuid = cameraPicture.Uuid()
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-time.After(nextImageDelay(cameraPicture, view)):
+		}
+
+		cameraPicture = cameraClient.GetResizedImageWithOptions(view.RefreshInterval(), dim, view.JpgQuality(), options)
+		if isShutdown(cameraPicture) {
+			return
+		}
+	}
+}
+
+// isShutdown returns true when the camera client was shut down by a configuration reload.
+// Long-running requests then end such that clients reconnect and get the new camera client.
+func isShutdown(cp cameraClient.CameraPicture) bool {
+	return errors.Is(cp.Err(), cameraClient.ErrShutdown)
+}
+
+// nextImageDelay returns how long to wait until a new image can be fetched.
+// The expiry of an image includes the RefreshInterval of the view.
+func nextImageDelay(cp cameraClient.CameraPicture, view *config.ViewConfig) time.Duration {
+	wait := time.Until(cp.Expires())
+	if cp.Err() != nil || wait <= 0 {
+		return view.RefreshInterval()
+	}
+	return wait
+}
+
+func writeStreamPart(c *gin.Context, cp cameraClient.CameraPicture) error {
+	img := cp.JpgImg()
+	header := fmt.Sprintf(
+		"--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\nX-Fetched-At: %s\r\n\r\n",
+		streamBoundary, len(img), cp.Fetched().Format(time.RFC3339Nano),
+	)
+
+	if _, err := c.Writer.WriteString(header); err != nil {
+		return err
+	}
+	if _, err := c.Writer.Write(img); err != nil {
+		return err
+	}
+	if _, err := c.Writer.WriteString("\r\n"); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/jessevdk/go-flags"
 	"github.com/koestler/go-webcam/config"
+	"github.com/koestler/go-webcam/hashStore"
 	"log"
 	"os"
 	"os/signal"
@@ -16,10 +17,11 @@ var buildVersion string
 var buildTime string
 
 type CmdOptions struct {
-	Version    bool           `long:"version" description:"Print the build version and timestamp"`
-	Config     flags.Filename `short:"c" long:"config" description:"Config File in yaml format" default:"./config.yaml"`
-	CpuProfile flags.Filename `long:"cpuprofile" description:"write cpu profile to <file>"`
-	MemProfile flags.Filename `long:"memprofile" description:"write memory profile to <file>"`
+	Version     bool           `long:"version" description:"Print the build version and timestamp"`
+	Config      flags.Filename `short:"c" long:"config" description:"Config File in yaml format" default:"./config.yaml"`
+	CpuProfile  flags.Filename `long:"cpuprofile" description:"write cpu profile to <file>"`
+	MemProfile  flags.Filename `long:"memprofile" description:"write memory profile to <file>"`
+	WatchConfig bool           `long:"watch-config" description:"Reload the configuration whenever the config file changes"`
 }
 
 const (
@@ -76,8 +78,9 @@ func main() {
 
 	// call defer statements before os.Exit
 	exitCode := func() (exitCode int) {
-		// whenever an error is pushed to this chan, main is terminated
-		initiateShutdown := make(chan error)
+		// whenever an error is pushed to this chan, main is terminated;
+		// it is buffered since it may be written to before the main loop is running
+		initiateShutdown := make(chan error, 1)
 
 		if cfg.LogWorkerStart() {
 			log.Printf("main: start go-webcam version=%s", buildVersion)
@@ -88,20 +91,37 @@ func main() {
 			defer pprof.StopCPUProfile()
 		}
 
+		d := &daemon{
+			cmdOptions: cmdOptions,
+			cmdName:    cmdName,
+			cfg:        cfg,
+		}
+
 		// start camera clients
-		cameraClientPoolInstance := runCameraClient(cfg, initiateShutdown)
-		defer cameraClientPoolInstance.Shutdown()
+		d.cameraClientPoolInstance = runCameraClient(cfg, initiateShutdown)
+		defer d.cameraClientPoolInstance.Shutdown()
+
+		// start mqtt clients; they may be replaced on reload
+		d.mqttClientPoolInstance = runMqttClient(cfg, d.cameraClientPoolInstance)
+		defer func() { d.mqttClientPoolInstance.Shutdown() }()
+
+		// start archive; it may be replaced on reload
+		d.archiveInstance = runArchive(cfg, d.cameraClientPoolInstance)
+		defer func() {
+			if d.archiveInstance != nil {
+				d.archiveInstance.Shutdown()
+			}
+		}()
 
 		// start http server
-		httpServerInstance := runHttpServer(cfg, cameraClientPoolInstance)
-		defer httpServerInstance.Shutdown()
-
-		// start mqtt clients
-		clientPoolInstance := runMqttClient(cfg)
-		defer clientPoolInstance.Shutdown()
+		d.hashStorage = hashStore.Run(cfg.HttpServer())
+		d.httpServerInstance = runHttpServer(
+			cfg, d.cameraClientPoolInstance, d.mqttClientPoolInstance, d.archiveInstance, d.hashStorage,
+		)
+		defer d.httpServerInstance.Shutdown()
 
 		if cfg.LogWorkerStart() {
-			log.Print("main: start completed; run until SIGTERM or SIGINT is received")
+			log.Print("main: start completed; run until SIGTERM or SIGINT is received; reload on SIGHUP")
 		}
 
 		// setup SIGTERM, SIGINT handlers
@@ -109,16 +129,35 @@ func main() {
 		signal.Notify(gracefulStop, syscall.SIGTERM)
 		signal.Notify(gracefulStop, syscall.SIGINT)
 
+		// setup SIGHUP handler and the optional config file watcher to reload the configuration
+		reloadSignal := make(chan os.Signal, 1)
+		signal.Notify(reloadSignal, syscall.SIGHUP)
+		var configChanged <-chan struct{}
+		if cmdOptions.WatchConfig {
+			configChanged = watchConfigFile(string(cmdOptions.Config))
+		}
+
 		// wait for something to trigger a shutdown
-		select {
-		case err := <-initiateShutdown:
-			log.Printf("main: forced shutdown due to fatal error: %s", err)
-			exitCode = ExitDueToModuleStart
-		case sig := <-gracefulStop:
-			if cfg.LogWorkerStart() {
-				log.Printf("main: graceful shutdown; caught signal: %+v", sig)
+	loop:
+		for {
+			select {
+			case err := <-initiateShutdown:
+				log.Printf("main: forced shutdown due to fatal error: %s", err)
+				exitCode = ExitDueToModuleStart
+				break loop
+			case sig := <-gracefulStop:
+				if d.cfg.LogWorkerStart() {
+					log.Printf("main: graceful shutdown; caught signal: %+v", sig)
+				}
+				exitCode = ExitSuccess
+				break loop
+			case sig := <-reloadSignal:
+				log.Printf("main: reload configuration; caught signal: %+v", sig)
+				d.reload()
+			case <-configChanged:
+				log.Print("main: reload configuration; config file changed")
+				d.reload()
 			}
-			exitCode = ExitSuccess
 		}
 
 		// write memory profile; after that defer will run the shutdown methods
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"github.com/koestler/go-webcam/cameraClient"
 	"github.com/koestler/go-webcam/config"
 	"github.com/koestler/go-webcam/mqttClient"
 	"log"
 )
 
-func runMqttClient(cfg *config.Config) (clientPoolInstance *mqttClient.ClientPool) {
+func runMqttClient(
+	cfg *config.Config,
+	cameraClientPoolInstance *cameraClient.ClientPool,
+) (clientPoolInstance *mqttClient.ClientPool) {
 	clientPoolInstance = mqttClient.RunPool()
 
 	for _, cfgClient := range cfg.MqttClients() {
@@ -22,6 +26,14 @@ func runMqttClient(cfg *config.Config) (clientPoolInstance *mqttClient.ClientPoo
 			log.Printf("mqttClient[%s]: start failed: %s", cfgClient.Name(), err)
 		} else {
 			clientPoolInstance.AddClient(client)
+			cameras := make([]*cameraClient.Client, 0, len(cfg.Cameras()))
+			for _, camera := range cfg.Cameras() {
+				if cc := cameraClientPoolInstance.GetClient(camera.Name()); cc != nil {
+					client.RunCameraPublisher(cc)
+					cameras = append(cameras, cc)
+				}
+			}
+			client.RunHomeAssistantDiscovery(cameras)
 			if cfg.LogWorkerStart() {
 				log.Printf(
 					"mqttClient[%s]: started",
@@ -0,0 +1,197 @@
+package mqttClient
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/pkg/errors"
+)
+
+type cameraStatusMessage struct {
+	Fetched         time.Time `json:"fetched"`
+	FetchDurationMs int64     `json:"fetchDurationMs"`
+	Width           int       `json:"width"`
+	Height          int       `json:"height"`
+	Error           string    `json:"error,omitempty"`
+}
+
+type imageDimension struct {
+	width  int
+	height int
+}
+
+func (d imageDimension) Width() int {
+	return d.width
+}
+
+func (d imageDimension) Height() int {
+	return d.height
+}
+
+// RunCameraPublisher publishes the status and optionally a resized image of the given camera
+// every CameraPublishInterval. Changes between ok and failing fetches are published immediately.
+// Commands received on the command topic are executed and their result is published immediately.
+// Motion events are published to the motion topic when motion detection is enabled for the camera.
+func (c *Client) RunCameraPublisher(camera *cameraClient.Client) {
+	c.runMotionPublisher(camera)
+
+	statusTopic := getCameraTopic(c.cfg.CameraStatusTopic(), c.cfg, camera.Name())
+	imageTopic := getCameraTopic(c.cfg.CameraImageTopic(), c.cfg, camera.Name())
+	commandTopic := getCameraTopic(c.cfg.CameraCommandTopic(), c.cfg, camera.Name())
+	if len(statusTopic) < 1 && len(imageTopic) < 1 && len(commandTopic) < 1 {
+		return
+	}
+
+	commands := make(chan string, 4)
+	if len(commandTopic) > 0 {
+		c.subscribe(commandTopic, func(_ mqtt.Client, msg mqtt.Message) {
+			select {
+			case commands <- string(msg.Payload()):
+			case <-c.shutdown:
+			}
+		})
+	}
+
+	go func() {
+		images, unsubscribe := camera.SubscribeRawImages()
+		defer unsubscribe()
+
+		ticker := time.NewTicker(c.cfg.CameraPublishInterval())
+		defer ticker.Stop()
+
+		lastUuid := ""
+		lastFailed := false
+		publish := func(cp cameraClient.CameraPicture) {
+			if errors.Is(cp.Err(), cameraClient.ErrShutdown) {
+				// the camera is being replaced, eg. during a reload; its retained status must not be overwritten
+				return
+			}
+			lastUuid = cp.Uuid()
+			lastFailed = cp.Err() != nil
+
+			if len(statusTopic) < 1 && len(imageTopic) < 1 {
+				return
+			}
+
+			if len(statusTopic) > 0 {
+				c.publishCameraStatus(statusTopic, cp)
+			}
+			if len(imageTopic) > 0 && !lastFailed {
+				c.publishCameraImage(imageTopic, camera)
+			}
+		}
+
+		// the delayed image triggers a fetch when the cached one is expired
+		publish(camera.GetDelayedImage(c.cfg.CameraPublishInterval()))
+
+		for {
+			select {
+			case <-c.shutdown:
+				return
+			case <-camera.Done():
+				return
+			case <-ticker.C:
+				if cp := camera.GetDelayedImage(c.cfg.CameraPublishInterval()); cp.Uuid() != lastUuid {
+					publish(cp)
+				}
+			case cp := <-images:
+				if (cp.Err() != nil) != lastFailed {
+					publish(cp)
+				}
+			case command := <-commands:
+				if c.handleCameraCommand(camera, command) {
+					publish(camera.GetDelayedImage(c.cfg.CameraPublishInterval()))
+				}
+			}
+		}
+	}()
+}
+
+// handleCameraCommand executes one of the following commands:
+// fetch: fetch a new image immediately, bypassing all caches
+// preemptive [duration]: fetch images every RefreshInterval for the given duration (default 1m) or until stop
+// stop: stop preemptive fetching
+// It returns true when a new image was fetched.
+func (c *Client) handleCameraCommand(camera *cameraClient.Client, command string) (fetched bool) {
+	if c.cfg.LogDebug() {
+		log.Printf("mqttClient[%s]: camera=%s received command '%s'", c.cfg.Name(), camera.Name(), command)
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) < 1 {
+		return false
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "fetch":
+		camera.FetchImage()
+		return true
+	case "preemptive":
+		duration := time.Minute
+		if len(fields) > 1 {
+			d, err := time.ParseDuration(fields[1])
+			if err != nil || d <= 0 {
+				log.Printf("mqttClient[%s]: camera=%s invalid preemptive duration '%s'", c.cfg.Name(), camera.Name(), fields[1])
+				return false
+			}
+			duration = d
+		}
+		camera.PreemptiveFetchFor(duration)
+		// trigger a first fetch right away
+		camera.FetchImage()
+		return true
+	case "stop":
+		camera.PreemptiveFetchFor(0)
+	default:
+		log.Printf("mqttClient[%s]: camera=%s unknown command '%s'", c.cfg.Name(), camera.Name(), command)
+	}
+	return false
+}
+
+func (c *Client) publishCameraStatus(topic string, cp cameraClient.CameraPicture) {
+	dim := cameraClient.DimensionOfImage(cp.DecodedImg())
+	msg := cameraStatusMessage{
+		Fetched:         cp.Fetched(),
+		FetchDurationMs: cp.FetchDuration().Milliseconds(),
+		Width:           dim.Width(),
+		Height:          dim.Height(),
+	}
+	if err := cp.Err(); err != nil {
+		msg.Error = err.Error()
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("mqttClient[%s]: cannot encode camera status: %s", c.cfg.Name(), err)
+		return
+	}
+
+	c.mqttClient.Publish(topic, c.cfg.Qos(), true, payload)
+	if c.cfg.LogDebug() {
+		log.Printf("mqttClient[%s]: published camera status to %s", c.cfg.Name(), topic)
+	}
+}
+
+func (c *Client) publishCameraImage(topic string, camera *cameraClient.Client) {
+	cp := camera.GetResizedImage(
+		c.cfg.CameraPublishInterval(),
+		imageDimension{c.cfg.CameraImageMaxWidth(), c.cfg.CameraImageMaxHeight()},
+		c.cfg.CameraImageJpgQuality(),
+	)
+	if cp.Err() != nil {
+		return
+	}
+
+	c.mqttClient.Publish(topic, c.cfg.Qos(), true, cp.JpgImg())
+	if c.cfg.LogDebug() {
+		log.Printf("mqttClient[%s]: published camera image to %s", c.cfg.Name(), topic)
+	}
+}
+
+func getCameraTopic(template string, cfg Config, cameraName string) string {
+	return strings.Replace(replaceTemplate(template, cfg), "%Camera%", cameraName, 1)
+}
@@ -30,7 +30,7 @@ func (p *ClientPool) AddClient(client *Client) {
 	p.Clients[client.Config().Name()] = client
 }
 
-func (p *ClientPool) RemoveClient(client Client) {
+func (p *ClientPool) RemoveClient(client *Client) {
 	p.ClientsMutex.Lock()
 	defer p.ClientsMutex.Unlock()
 	delete(p.Clients, client.Config().Name())
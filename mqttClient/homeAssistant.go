@@ -0,0 +1,146 @@
+package mqttClient
+
+import (
+	"encoding/json"
+	"log"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/koestler/go-webcam/cameraClient"
+)
+
+type haDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+}
+
+type haDiscoveryMessage struct {
+	Name                string   `json:"name"`
+	UniqueId            string   `json:"unique_id"`
+	Device              haDevice `json:"device"`
+	AvailabilityTopic   string   `json:"availability_topic,omitempty"`
+	Topic               string   `json:"topic,omitempty"`
+	StateTopic          string   `json:"state_topic,omitempty"`
+	ValueTemplate       string   `json:"value_template,omitempty"`
+	DeviceClass         string   `json:"device_class,omitempty"`
+	EntityCategory      string   `json:"entity_category,omitempty"`
+	PayloadAvailable    string   `json:"payload_available,omitempty"`
+	PayloadNotAvailable string   `json:"payload_not_available,omitempty"`
+}
+
+type haEntity struct {
+	component string
+	objectId  string
+	message   haDiscoveryMessage
+}
+
+// RunHomeAssistantDiscovery publishes Home Assistant discovery configs for the given cameras after every connect.
+// Each camera gets a camera entity showing the image topic, a last fetch time sensor and a problem sensor.
+// Cameras with motion detection additionally get a motion sensor.
+// Configs published earlier but no longer part of the current set of entities are removed.
+func (c *Client) RunHomeAssistantDiscovery(cameras []*cameraClient.Client) {
+	if !c.cfg.HomeAssistantDiscovery() {
+		return
+	}
+
+	entities := make([]haEntity, 0, 4*len(cameras))
+	for _, camera := range cameras {
+		entities = append(entities, c.haEntities(camera)...)
+	}
+
+	known := make(map[string]struct{}, len(entities))
+	for _, entity := range entities {
+		known[c.haTopic(entity.component, entity.objectId)] = struct{}{}
+	}
+
+	// remove configs of entities which are no longer configured; they are received as retained messages
+	c.subscribe(c.haTopic("+", "+"), func(client mqtt.Client, msg mqtt.Message) {
+		if len(msg.Payload()) < 1 {
+			return
+		}
+		if _, ok := known[msg.Topic()]; ok {
+			return
+		}
+		if c.cfg.LogDebug() {
+			log.Printf("mqttClient[%s]: remove home assistant config %s", c.cfg.Name(), msg.Topic())
+		}
+		client.Publish(msg.Topic(), c.cfg.Qos(), true, []byte{})
+	})
+
+	c.onConnect(func() {
+		for _, entity := range entities {
+			payload, err := json.Marshal(entity.message)
+			if err != nil {
+				log.Printf("mqttClient[%s]: cannot encode home assistant config: %s", c.cfg.Name(), err)
+				continue
+			}
+			c.mqttClient.Publish(c.haTopic(entity.component, entity.objectId), c.cfg.Qos(), true, payload)
+		}
+		if c.cfg.LogDebug() {
+			log.Printf("mqttClient[%s]: published home assistant discovery configs", c.cfg.Name())
+		}
+	})
+}
+
+func (c *Client) haTopic(component, objectId string) string {
+	return c.cfg.HomeAssistantDiscoveryPrefix() + "/" + component + "/" + c.cfg.HomeAssistantNodeId() + "/" + objectId + "/config"
+}
+
+func (c *Client) haEntities(camera *cameraClient.Client) []haEntity {
+	cameraName := camera.Name()
+	nodeId := c.cfg.HomeAssistantNodeId()
+	device := haDevice{
+		Identifiers:  []string{nodeId + "-" + cameraName},
+		Name:         cameraName,
+		Manufacturer: "go-webcam",
+	}
+	base := haDiscoveryMessage{
+		Device:            device,
+		AvailabilityTopic: getAvailabilityTopic(c.cfg),
+	}
+	if len(base.AvailabilityTopic) > 0 {
+		base.PayloadAvailable = "online"
+		base.PayloadNotAvailable = "offline"
+	}
+	statusTopic := getCameraTopic(c.cfg.CameraStatusTopic(), c.cfg, cameraName)
+
+	cameraEntity := base
+	cameraEntity.Name = cameraName
+	cameraEntity.UniqueId = nodeId + "_" + cameraName
+	cameraEntity.Topic = getCameraTopic(c.cfg.CameraImageTopic(), c.cfg, cameraName)
+
+	fetched := base
+	fetched.Name = cameraName + " last fetch"
+	fetched.UniqueId = nodeId + "_" + cameraName + "_fetched"
+	fetched.StateTopic = statusTopic
+	fetched.ValueTemplate = "{{ value_json.fetched }}"
+	fetched.DeviceClass = "timestamp"
+	fetched.EntityCategory = "diagnostic"
+
+	health := base
+	health.Name = cameraName + " problem"
+	health.UniqueId = nodeId + "_" + cameraName + "_problem"
+	health.StateTopic = statusTopic
+	health.ValueTemplate = "{{ 'ON' if value_json.error is defined else 'OFF' }}"
+	health.DeviceClass = "problem"
+	health.EntityCategory = "diagnostic"
+
+	entities := []haEntity{
+		{"camera", cameraName, cameraEntity},
+		{"sensor", cameraName + "_fetched", fetched},
+		{"binary_sensor", cameraName + "_problem", health},
+	}
+
+	if motionTopic := getCameraTopic(c.cfg.CameraMotionTopic(), c.cfg, cameraName); len(motionTopic) > 0 &&
+		camera.Config().MotionDetection().Enabled() {
+		motion := base
+		motion.Name = cameraName + " motion"
+		motion.UniqueId = nodeId + "_" + cameraName + "_motion"
+		motion.StateTopic = motionTopic
+		motion.ValueTemplate = "{{ 'ON' if value_json.active else 'OFF' }}"
+		motion.DeviceClass = "motion"
+		entities = append(entities, haEntity{"binary_sensor", cameraName + "_motion", motion})
+	}
+
+	return entities
+}
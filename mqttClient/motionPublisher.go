@@ -0,0 +1,73 @@
+package mqttClient
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/koestler/go-webcam/cameraClient"
+)
+
+type cameraMotionMessage struct {
+	Active bool       `json:"active"`
+	Start  *time.Time `json:"start,omitempty"`
+	End    *time.Time `json:"end,omitempty"`
+	Area   float64    `json:"area"`
+}
+
+// runMotionPublisher publishes a retained message to the motion topic whenever motion starts or ends.
+// Nothing is published for cameras without motion detection.
+func (c *Client) runMotionPublisher(camera *cameraClient.Client) {
+	topic := getCameraTopic(c.cfg.CameraMotionTopic(), c.cfg, camera.Name())
+	if len(topic) < 1 || !camera.Config().MotionDetection().Enabled() {
+		return
+	}
+
+	go func() {
+		events, unsubscribe := camera.SubscribeMotionEvents()
+		defer unsubscribe()
+
+		// replace a possibly outdated retained message by the current state
+		stats := camera.Stats()
+		c.publishMotionEvent(topic, cameraClient.MotionEvent{
+			Camera: camera.Name(),
+			Active: stats.MotionActive,
+			Start:  stats.LastMotionStart,
+			End:    stats.LastMotionEnd,
+			Area:   stats.LastMotionArea,
+		})
+
+		for {
+			select {
+			case <-c.shutdown:
+				return
+			case event := <-events:
+				c.publishMotionEvent(topic, event)
+			}
+		}
+	}()
+}
+
+func (c *Client) publishMotionEvent(topic string, event cameraClient.MotionEvent) {
+	msg := cameraMotionMessage{
+		Active: event.Active,
+		Area:   event.Area,
+	}
+	if !event.Start.IsZero() {
+		msg.Start = &event.Start
+	}
+	if !event.End.IsZero() {
+		msg.End = &event.End
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("mqttClient[%s]: cannot encode motion event: %s", c.cfg.Name(), err)
+		return
+	}
+
+	c.mqttClient.Publish(topic, c.cfg.Qos(), true, payload)
+	if c.cfg.LogDebug() {
+		log.Printf("mqttClient[%s]: published motion event to %s", c.cfg.Name(), topic)
+	}
+}
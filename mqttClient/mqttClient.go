@@ -6,12 +6,19 @@ import (
 	"log"
 	"os"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Client struct {
 	cfg        Config
 	mqttClient mqtt.Client
 	shutdown   chan struct{}
+
+	// subscriptions are renewed after every connect since a clean session is used
+	subscriptions      map[string]mqtt.MessageHandler
+	onConnectHandlers  []func()
+	subscriptionsMutex sync.Mutex
 }
 
 type Config interface {
@@ -24,15 +31,32 @@ type Config interface {
 	TopicPrefix() string
 	AvailabilityTopic() string
 	LogDebug() bool
+	CameraStatusTopic() string
+	CameraImageTopic() string
+	CameraCommandTopic() string
+	CameraMotionTopic() string
+	CameraPublishInterval() time.Duration
+	CameraImageMaxWidth() int
+	CameraImageMaxHeight() int
+	CameraImageJpgQuality() int
+	HomeAssistantDiscovery() bool
+	HomeAssistantDiscoveryPrefix() string
+	HomeAssistantNodeId() string
 }
 
 func RunClient(cfg Config) (*Client, error) {
+	clientStruct := &Client{
+		cfg:           cfg,
+		shutdown:      make(chan struct{}),
+		subscriptions: make(map[string]mqtt.MessageHandler),
+	}
+
 	// configure client and start connection
 	opts := mqtt.NewClientOptions().
 		AddBroker(cfg.Broker()).
 		SetClientID(cfg.ClientId()).
 		SetOrderMatters(false).
-		SetCleanSession(true) // use clean, non-persistent session since we only publish
+		SetCleanSession(true) // use clean, non-persistent session; subscriptions are renewed on connect
 
 	if user := cfg.User(); len(user) > 0 {
 		opts.SetUsername(user)
@@ -42,32 +66,43 @@ func RunClient(cfg Config) (*Client, error) {
 	}
 
 	// setup availability topic using will
-	if availabilityTopic := getAvailabilityTopic(cfg); len(availabilityTopic) > 0 {
+	availabilityTopic := getAvailabilityTopic(cfg)
+	if len(availabilityTopic) > 0 {
 		opts.SetWill(availabilityTopic, "offline", cfg.Qos(), true)
+	}
 
+	opts.SetOnConnectHandler(func(client mqtt.Client) {
 		// publish availability after each connect
-		opts.SetOnConnectHandler(func(client mqtt.Client) {
+		if len(availabilityTopic) > 0 {
 			client.Publish(availabilityTopic, cfg.Qos(), true, "online")
-		})
-	}
+		}
+
+		clientStruct.subscriptionsMutex.Lock()
+		defer clientStruct.subscriptionsMutex.Unlock()
+		for topic, handler := range clientStruct.subscriptions {
+			client.Subscribe(topic, cfg.Qos(), handler)
+		}
+		for _, handler := range clientStruct.onConnectHandlers {
+			go handler()
+		}
+	})
 
 	mqtt.ERROR = log.New(os.Stdout, "", 0)
 	if cfg.LogDebug() {
 		mqtt.DEBUG = log.New(os.Stdout, "", 0)
 	}
 
-	mqttClient := mqtt.NewClient(opts)
-	if token := mqttClient.Connect(); token.Wait() && token.Error() != nil {
+	clientStruct.mqttClient = mqtt.NewClient(opts)
+	if token := clientStruct.mqttClient.Connect(); token.Wait() && token.Error() != nil {
 		return nil, fmt.Errorf("connect failed: %s", token.Error())
 	}
 
-	clientStruct := Client{
-		cfg:        cfg,
-		mqttClient: mqttClient,
-		shutdown:   make(chan struct{}),
-	}
+	return clientStruct, nil
+}
 
-	return &clientStruct, nil
+// IsConnected returns true when the connection to the broker is established.
+func (c *Client) IsConnected() bool {
+	return c.mqttClient.IsConnectionOpen()
 }
 
 func (c *Client) Config() Config {
@@ -86,6 +121,27 @@ func (c *Client) Shutdown() {
 	log.Printf("mqttClient[%s]: shutdown completed", c.cfg.Name())
 }
 
+func (c *Client) subscribe(topic string, handler mqtt.MessageHandler) {
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	c.subscriptions[topic] = handler
+	if c.mqttClient.IsConnectionOpen() {
+		c.mqttClient.Subscribe(topic, c.cfg.Qos(), handler)
+	}
+}
+
+// onConnect runs the given handler now, if connected, and after every reconnect.
+func (c *Client) onConnect(handler func()) {
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	c.onConnectHandlers = append(c.onConnectHandlers, handler)
+	if c.mqttClient.IsConnectionOpen() {
+		go handler()
+	}
+}
+
 func getAvailabilityTopic(cfg Config) string {
 	return replaceTemplate(cfg.AvailabilityTopic(), cfg)
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"github.com/koestler/go-webcam/archive"
+	"github.com/koestler/go-webcam/cameraClient"
+	"github.com/koestler/go-webcam/config"
+	"github.com/koestler/go-webcam/hashStore"
+	"github.com/koestler/go-webcam/httpServer"
+	"github.com/koestler/go-webcam/mqttClient"
+	"log"
+	"os"
+	"reflect"
+	"time"
+)
+
+// configWatchInterval defines how often the config file is checked for changes when --watch-config is set.
+const configWatchInterval = 2 * time.Second
+
+// daemon holds the running configuration and modules; it is used to apply a new configuration at runtime.
+type daemon struct {
+	cmdOptions               CmdOptions
+	cmdName                  string
+	cfg                      *config.Config
+	cameraClientPoolInstance *cameraClient.ClientPool
+	mqttClientPoolInstance   *mqttClient.ClientPool
+	archiveInstance          *archive.Archive
+	hashStorage              *hashStore.HashStore
+	httpServerInstance       *httpServer.HttpServer
+}
+
+// reload reads the config file again and applies the changes to the running modules.
+// Cameras that did not change keep running and keep their cached images.
+// When the new configuration is invalid, the old one stays active.
+func (d *daemon) reload() {
+	cfg, err := config.ReadConfigFile(d.cmdName, string(d.cmdOptions.Config))
+	if len(err) > 0 {
+		for _, e := range err {
+			log.Printf("config: error: %v", e)
+		}
+		log.Print("main: reload failed; keep running on the old configuration")
+		return
+	}
+
+	if cfg.LogConfig() {
+		if err := cfg.PrintConfig(); err != nil {
+			log.Printf("config: cannot print: %s", err)
+		}
+	}
+
+	// recorders wait for images of their camera client; stop them before any camera client is stopped
+	if d.archiveInstance != nil {
+		d.archiveInstance.Shutdown()
+		d.archiveInstance = nil
+	}
+
+	camerasChanged := d.reloadCameras(&cfg)
+
+	if camerasChanged || !reflect.DeepEqual(d.cfg.MqttClients(), cfg.MqttClients()) {
+		// camera publishers are bound to a camera client; restart all mqtt clients to attach them to the new ones
+		d.mqttClientPoolInstance.Shutdown()
+		d.mqttClientPoolInstance = runMqttClient(&cfg, d.cameraClientPoolInstance)
+	}
+
+	d.archiveInstance = runArchive(&cfg, d.cameraClientPoolInstance)
+
+	if d.httpServerInstance != nil {
+		d.httpServerInstance.Reload(
+			getHttpServerEnvironment(
+				&cfg, d.cameraClientPoolInstance, d.mqttClientPoolInstance, d.archiveInstance, d.hashStorage,
+			),
+		)
+	} else if cfg.HttpServer().Enabled() {
+		log.Print("main: reload: enabling the http server requires a restart")
+	}
+
+	d.cfg = &cfg
+
+	if cfg.LogWorkerStart() {
+		log.Print("main: reload completed")
+	}
+}
+
+// reloadCameras stops all camera clients which were removed or changed and starts all new or changed ones.
+// It returns true if any camera client was stopped or started.
+func (d *daemon) reloadCameras(cfg *config.Config) (changed bool) {
+	cameras := make(map[string]*config.CameraConfig, len(cfg.Cameras()))
+	for _, camera := range cfg.Cameras() {
+		cameras[camera.Name()] = camera
+	}
+
+	for _, client := range d.cameraClientPoolInstance.GetClients() {
+		camera, ok := cameras[client.Name()]
+		if ok && reflect.DeepEqual(client.Config(), getCameraClientConfig(cfg, camera)) {
+			continue
+		}
+
+		if cfg.LogWorkerStart() {
+			log.Printf("cameraClient[%s]: stop", client.Name())
+		}
+		d.cameraClientPoolInstance.RemoveClient(client)
+		client.Shutdown()
+		changed = true
+	}
+
+	for _, camera := range cfg.Cameras() {
+		if d.cameraClientPoolInstance.GetClient(camera.Name()) != nil {
+			continue
+		}
+		if client := startCameraClient(cfg, camera); client != nil {
+			d.cameraClientPoolInstance.AddClient(client)
+		}
+		changed = true
+	}
+
+	return
+}
+
+// watchConfigFile sends to the returned channel whenever the modification time or size of the config file changes.
+func watchConfigFile(path string) <-chan struct{} {
+	changed := make(chan struct{})
+
+	go func() {
+		var lastModTime time.Time
+		var lastSize int64
+		if info, err := os.Stat(path); err == nil {
+			lastModTime, lastSize = info.ModTime(), info.Size()
+		}
+
+		ticker := time.NewTicker(configWatchInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			info, err := os.Stat(path)
+			if err != nil {
+				// the file may be replaced by an editor; try again on the next tick
+				continue
+			}
+			if info.ModTime().Equal(lastModTime) && info.Size() == lastSize {
+				continue
+			}
+			lastModTime, lastSize = info.ModTime(), info.Size()
+			changed <- struct{}{}
+		}
+	}()
+
+	return changed
+}